@@ -0,0 +1,109 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+// sibylQuery filters the sibyl's ban list using the text query syntax of
+// the banQuery package, and writes the matched bans to the standard output.
+//
+//	sibylQuery -token TOKEN -query 'flag:SPAMBOT by:123 since:7d coef>300'
+//	sibylQuery -input bans.json -query 'reason~^raid' -format markdown
+//
+// the input file can be either a getBans result, or a mirror snapshot.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/banIO"
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/banQuery"
+)
+
+func main() {
+	token := flag.String("token", os.Getenv("SIBYL_TOKEN"), "sibyl token (defaults to $SIBYL_TOKEN)")
+	hostUrl := flag.String("url", sibylSystem.DefaultUrl, "sibyl host url")
+	input := flag.String("input", "", "read the bans from this json file instead of the server")
+	query := flag.String("query", "", "filter query, e.g. 'flag:SPAMBOT coef>300'")
+	format := flag.String("format", "csv", "output format: csv, json, ndjson or markdown")
+	columns := flag.String("columns", "", "comma separated columns to export")
+	flag.Parse()
+
+	if err := run(*token, *hostUrl, *input, *query, *format, *columns); err != nil {
+		fmt.Fprintln(os.Stderr, "sibylQuery:", err)
+		os.Exit(1)
+	}
+}
+
+func run(token, hostUrl, input, query, format, columns string) error {
+	filter, err := banQuery.Parse(query)
+	if err != nil {
+		return err
+	}
+
+	outputFormat := banIO.DetectFormat("." + format)
+	if outputFormat == banIO.FormatUnknown {
+		return banIO.ErrUnknownFormat
+	}
+
+	var selected []banIO.Column
+	for _, current := range strings.Split(columns, ",") {
+		if current = strings.TrimSpace(current); current != "" {
+			selected = append(selected, banIO.Column(current))
+		}
+	}
+
+	exporter, err := banIO.NewBanExporter(os.Stdout, outputFormat, selected...)
+	if err != nil {
+		return err
+	}
+
+	var writeErr error
+	write := func(info *sibylSystem.BanInfo) bool {
+		if filter.Match(info) {
+			writeErr = exporter.Write(info)
+		}
+		return writeErr == nil
+	}
+
+	if input != "" {
+		err = readInput(input, write)
+	} else {
+		client := sibylSystem.NewClient(token, &sibylSystem.SibylConfig{HostUrl: hostUrl})
+		err = client.StreamBannedUsers(context.Background(), write)
+	}
+
+	if err != nil {
+		return err
+	} else if writeErr != nil {
+		return writeErr
+	}
+
+	return exporter.Close()
+}
+
+func readInput(path string, fn func(info *sibylSystem.BanInfo) bool) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	result := new(sibylSystem.GetBansResult)
+	err = json.Unmarshal(b, result)
+	if err != nil {
+		return err
+	}
+
+	for i := range result.Users {
+		if !fn(&result.Users[i]) {
+			break
+		}
+	}
+
+	return nil
+}
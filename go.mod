@@ -10,8 +10,7 @@ go 1.18
 require (
 	github.com/ALiwoto/mdparser v1.1.2
 	github.com/AnimeKaizoku/ssg v1.1.20
-)
-
-require (
-	golang.org/x/text v0.3.7 // indirect
+	github.com/PaulSonOfLars/gotgbot/v2 v2.0.0-rc.20
+	golang.org/x/text v0.3.7
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,10 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banAllow
+
+// GlobalChatId is the chat id of the entries which allow the user in
+// all of the chats.
+const GlobalChatId int64 = 0
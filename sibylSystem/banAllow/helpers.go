@@ -0,0 +1,105 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banAllow
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+)
+
+// NewAllowlist creates a new allowlist; if the config contains a store,
+// the saved entries will be loaded from it.
+func NewAllowlist(config *AllowlistConfig) (*Allowlist, error) {
+	if config == nil {
+		config = GetDefaultAllowlistConfig()
+	}
+
+	a := &Allowlist{
+		mut:     &sync.RWMutex{},
+		store:   config.Store,
+		entries: make(map[entryKey]*Entry),
+		users:   make(map[int64]map[int64]*Entry),
+	}
+
+	if a.store == nil {
+		a.store = NewMemoryStore()
+	}
+
+	entries, err := a.store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, current := range entries {
+		if current != nil && current.UserId != 0 {
+			a.setEntry(current)
+		}
+	}
+
+	return a, nil
+}
+
+// GetDefaultAllowlistConfig returns default config of the allowlist.
+func GetDefaultAllowlistConfig() *AllowlistConfig {
+	return &AllowlistConfig{}
+}
+
+// NewMemoryStore returns a new store which only keeps the entries in
+// the memory.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		mut: &sync.Mutex{},
+	}
+}
+
+// NewFileStore returns a new store which saves the entries in the json
+// file at the given path.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{
+		mut:  &sync.Mutex{},
+		path: path,
+	}
+}
+
+// NewStatus returns the status of the user from its sibyl info.
+func NewStatus(info *sibylSystem.GetInfoResult) *Status {
+	return &Status{
+		Banned:           info.Banned,
+		Reason:           info.Reason,
+		CrimeCoefficient: info.CrimeCoefficient,
+		BanFlags:         append([]sibylSystem.BanFlag(nil), info.BanFlags...),
+		CheckedAt:        time.Now(),
+	}
+}
+
+func sortEntries(entries []*Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].ChatId != entries[j].ChatId {
+			return entries[i].ChatId < entries[j].ChatId
+		}
+		return entries[i].UserId < entries[j].UserId
+	})
+}
+
+func isSameStatus(s1, s2 *Status) bool {
+	if s1.Banned != s2.Banned ||
+		s1.Reason != s2.Reason ||
+		s1.CrimeCoefficient != s2.CrimeCoefficient ||
+		len(s1.BanFlags) != len(s2.BanFlags) {
+		return false
+	}
+
+	for i := range s1.BanFlags {
+		if s1.BanFlags[i] != s2.BanFlags[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,495 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banAllow
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+)
+
+// Add adds the entry to the allowlist, replacing the previous entry of
+// the user in the same chat. AddedAt is set to now if it's zero. if the
+// store fails, the allowlist stays the same.
+func (a *Allowlist) Add(entry *Entry) error {
+	if entry == nil || entry.UserId == 0 {
+		return ErrInvalidEntry
+	}
+
+	if strings.TrimSpace(entry.Reason) == "" {
+		return ErrNoReason
+	}
+
+	entry = entry.clone()
+	if entry.AddedAt.IsZero() {
+		entry.AddedAt = time.Now()
+	}
+
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	previous := a.entries[entry.getKey()]
+	a.setEntry(entry)
+	err := a.save()
+	if err != nil {
+		// keep the memory the same as the store.
+		if previous != nil {
+			a.setEntry(previous)
+		} else {
+			a.deleteEntry(entry.getKey())
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Allow allows the user in the chat (or in all chats, if chatId is
+// GlobalChatId) for the given duration; a zero duration never expires.
+func (a *Allowlist) Allow(chatId, userId, addedBy int64, reason string, duration time.Duration) (*Entry, error) {
+	entry := &Entry{
+		ChatId:  chatId,
+		UserId:  userId,
+		Reason:  reason,
+		AddedBy: addedBy,
+		AddedAt: time.Now(),
+	}
+
+	if duration > 0 {
+		entry.ExpiresAt = entry.AddedAt.Add(duration)
+	}
+
+	err := a.Add(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// Remove removes the entry of the user in the chat; it returns false if
+// the user had no entry in the chat. if the store fails, the entry isn't
+// removed.
+func (a *Allowlist) Remove(chatId, userId int64) (bool, error) {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	key := entryKey{chatId: chatId, userId: userId}
+	previous := a.entries[key]
+	if previous == nil {
+		return false, nil
+	}
+
+	a.deleteEntry(key)
+	err := a.save()
+	if err != nil {
+		a.setEntry(previous)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Get returns a copy of the entry which allows the user in the chat; the
+// entry of the chat has priority over the global one. it returns nil if
+// the user isn't allowed in the chat.
+func (a *Allowlist) Get(chatId, userId int64) *Entry {
+	a.mut.RLock()
+	defer a.mut.RUnlock()
+
+	entry := a.getEntry(chatId, userId, time.Now())
+	if entry == nil {
+		return nil
+	}
+
+	return entry.clone()
+}
+
+// IsAllowed returns true if the user is allowed in the chat.
+func (a *Allowlist) IsAllowed(chatId, userId int64) bool {
+	a.mut.RLock()
+	defer a.mut.RUnlock()
+
+	return a.getEntry(chatId, userId, time.Now()) != nil
+}
+
+// CheckAllowed records the sibyl info of the user (if it's not nil) and
+// returns true if the user is allowed in the chat. it's meant to be
+// called with the result of GetInfo before acting on it; it can be used
+// as the Allowlist of sibylGuard.
+func (a *Allowlist) CheckAllowed(chatId, userId int64, info *sibylSystem.GetInfoResult) bool {
+	if info != nil {
+		a.Observe(info)
+	}
+
+	return a.IsAllowed(chatId, userId)
+}
+
+// Observe records the sibyl info of an allowlisted user, and returns the
+// changes of the status of the user's entries since the last time the
+// user was seen. the OnStatusChange callback is called for each change.
+// the first time a user is seen, the status is only recorded. the new
+// statuses aren't saved right away, see Flush.
+func (a *Allowlist) Observe(info *sibylSystem.GetInfoResult) []*StatusChange {
+	if info == nil || info.UserId == 0 {
+		return nil
+	}
+
+	status := NewStatus(info)
+
+	a.mut.RLock()
+	changed := a.hasNewStatus(info.UserId, status)
+	a.mut.RUnlock()
+
+	if !changed {
+		return nil
+	}
+
+	var changes []*StatusChange
+
+	a.mut.Lock()
+	for _, current := range a.users[info.UserId] {
+		previous := current.Status
+		if previous != nil && isSameStatus(previous, status) {
+			continue
+		}
+
+		current.Status = status
+		a.dirty = true
+		if previous != nil {
+			changes = append(changes, &StatusChange{
+				Entry:    current.clone(),
+				Previous: previous,
+				Current:  status,
+			})
+		}
+	}
+	onStatusChange := a.onStatusChange
+	a.mut.Unlock()
+
+	if onStatusChange == nil {
+		return changes
+	}
+
+	for _, current := range changes {
+		onStatusChange(current)
+	}
+
+	return changes
+}
+
+// Flush saves the statuses recorded by Observe, if they have changed
+// since the last time the entries were saved; the statuses are also
+// saved with any other change of the allowlist, and by Reconcile.
+func (a *Allowlist) Flush() error {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	if !a.dirty {
+		return nil
+	}
+
+	return a.save()
+}
+
+// Reconcile removes the expired entries, then fetches the sibyl info of
+// all of the allowlisted users and reports the changes of their status.
+func (a *Allowlist) Reconcile(ctx context.Context, client sibylSystem.SibylClient) (*ReconciliationReport, error) {
+	if client == nil {
+		return nil, ErrNoClient
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	report := &ReconciliationReport{
+		Failed: make(map[int64]error),
+	}
+
+	var err error
+	report.Expired, err = a.PurgeExpired()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, userId := range a.getUserIds() {
+		if err = ctx.Err(); err != nil {
+			return report, err
+		}
+
+		info, err := client.GetInfoWithContext(ctx, userId)
+		if err != nil {
+			report.Failed[userId] = err
+			continue
+		}
+
+		report.Checked++
+		report.Changes = append(report.Changes, a.Observe(info)...)
+	}
+
+	return report, a.Flush()
+}
+
+// PurgeExpired removes the expired entries and returns them; if they
+// can't be saved, nothing is removed.
+func (a *Allowlist) PurgeExpired() ([]*Entry, error) {
+	now := time.Now()
+
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	var expired []*Entry
+	for key, current := range a.entries {
+		if current.IsExpiredAt(now) {
+			expired = append(expired, current)
+			a.deleteEntry(key)
+		}
+	}
+
+	if len(expired) == 0 {
+		return nil, nil
+	}
+
+	err := a.save()
+	if err != nil {
+		for _, current := range expired {
+			a.setEntry(current)
+		}
+		return nil, err
+	}
+
+	sortEntries(expired)
+	return expired, nil
+}
+
+// GetEntries returns copies of all of the entries, including the expired
+// ones which haven't been purged yet.
+func (a *Allowlist) GetEntries() []*Entry {
+	a.mut.RLock()
+	defer a.mut.RUnlock()
+
+	return a.getEntries(func(*Entry) bool { return true })
+}
+
+// GetChatEntries returns copies of the entries of the chat, without the
+// global ones.
+func (a *Allowlist) GetChatEntries(chatId int64) []*Entry {
+	a.mut.RLock()
+	defer a.mut.RUnlock()
+
+	return a.getEntries(func(entry *Entry) bool {
+		return entry.ChatId == chatId
+	})
+}
+
+// Length returns the count of the entries.
+func (a *Allowlist) Length() int {
+	a.mut.RLock()
+	defer a.mut.RUnlock()
+
+	return len(a.entries)
+}
+
+// SetOnStatusChange sets a callback which is called when the sibyl status
+// of an allowlisted user changes, e.g. for notifying the admins of the
+// chat.
+func (a *Allowlist) SetOnStatusChange(fn func(*StatusChange)) {
+	a.mut.Lock()
+	a.onStatusChange = fn
+	a.mut.Unlock()
+}
+
+func (a *Allowlist) getEntry(chatId, userId int64, now time.Time) *Entry {
+	entry := a.entries[entryKey{chatId: chatId, userId: userId}]
+	if entry != nil && !entry.IsExpiredAt(now) {
+		return entry
+	}
+
+	entry = a.entries[entryKey{chatId: GlobalChatId, userId: userId}]
+	if entry != nil && !entry.IsExpiredAt(now) {
+		return entry
+	}
+
+	return nil
+}
+
+// hasNewStatus returns true if the status of any of the entries of the
+// user is different from the given one; the caller must hold the lock.
+func (a *Allowlist) hasNewStatus(userId int64, status *Status) bool {
+	for _, current := range a.users[userId] {
+		if current.Status == nil || !isSameStatus(current.Status, status) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setEntry adds the entry, replacing the previous entry of the user in
+// the same chat; the caller must hold the lock.
+func (a *Allowlist) setEntry(entry *Entry) {
+	a.entries[entry.getKey()] = entry
+
+	chats := a.users[entry.UserId]
+	if chats == nil {
+		chats = make(map[int64]*Entry)
+		a.users[entry.UserId] = chats
+	}
+	chats[entry.ChatId] = entry
+}
+
+// deleteEntry removes the entry; the caller must hold the lock.
+func (a *Allowlist) deleteEntry(key entryKey) {
+	delete(a.entries, key)
+
+	chats := a.users[key.userId]
+	delete(chats, key.chatId)
+	if len(chats) == 0 {
+		delete(a.users, key.userId)
+	}
+}
+
+func (a *Allowlist) getEntries(filter func(*Entry) bool) []*Entry {
+	var entries []*Entry
+	for _, current := range a.entries {
+		if filter(current) {
+			entries = append(entries, current.clone())
+		}
+	}
+
+	sortEntries(entries)
+	return entries
+}
+
+func (a *Allowlist) getUserIds() []int64 {
+	a.mut.RLock()
+	defer a.mut.RUnlock()
+
+	userIds := make([]int64, 0, len(a.users))
+	for userId := range a.users {
+		userIds = append(userIds, userId)
+	}
+
+	return userIds
+}
+
+// save saves the entries to the store; the caller must hold the lock.
+func (a *Allowlist) save() error {
+	entries := make([]*Entry, 0, len(a.entries))
+	for _, current := range a.entries {
+		entries = append(entries, current.clone())
+	}
+
+	sortEntries(entries)
+	err := a.store.Save(entries)
+	if err != nil {
+		return err
+	}
+
+	a.dirty = false
+	return nil
+}
+
+//---------------------------------------------------------
+
+// IsGlobal returns true if the entry allows the user in all of the chats.
+func (e *Entry) IsGlobal() bool {
+	return e.ChatId == GlobalChatId
+}
+
+// IsExpired returns true if the entry has expired.
+func (e *Entry) IsExpired() bool {
+	return e.IsExpiredAt(time.Now())
+}
+
+// IsExpiredAt returns true if the entry has expired at the given time.
+func (e *Entry) IsExpiredAt(t time.Time) bool {
+	return !e.ExpiresAt.IsZero() && !t.Before(e.ExpiresAt)
+}
+
+func (e *Entry) getKey() entryKey {
+	return entryKey{chatId: e.ChatId, userId: e.UserId}
+}
+
+func (e *Entry) clone() *Entry {
+	tmp := *e
+	return &tmp
+}
+
+//---------------------------------------------------------
+
+// IsBanned returns true if the user has been banned in sibyl since the
+// last time they were seen.
+func (c *StatusChange) IsBanned() bool {
+	return !c.Previous.Banned && c.Current.Banned
+}
+
+// IsUnbanned returns true if the user has been unbanned in sibyl since
+// the last time they were seen; the entry might not be needed anymore.
+func (c *StatusChange) IsUnbanned() bool {
+	return c.Previous.Banned && !c.Current.Banned
+}
+
+// IsChanged returns true if the user was and still is banned in sibyl,
+// but the ban (reason, flags or coefficient) has changed.
+func (c *StatusChange) IsChanged() bool {
+	return c.Previous.Banned && c.Current.Banned
+}
+
+//---------------------------------------------------------
+
+// HasChanges returns true if the status of any user has changed.
+func (r *ReconciliationReport) HasChanges() bool {
+	return len(r.Changes) != 0
+}
+
+//---------------------------------------------------------
+
+func (s *MemoryStore) Load() ([]*Entry, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	return s.entries, nil
+}
+
+func (s *MemoryStore) Save(entries []*Entry) error {
+	s.mut.Lock()
+	s.entries = entries
+	s.mut.Unlock()
+
+	return nil
+}
+
+//---------------------------------------------------------
+
+func (s *FileStore) Load() ([]*Entry, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	var entries []*Entry
+	_, err := sibylSystem.LoadJSONFile(s.path, &entries)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Save saves the entries using sibylSystem.SaveJSONFile, so a crash in the
+// middle of writing won't corrupt the previous entries.
+func (s *FileStore) Save(entries []*Entry) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	return sibylSystem.SaveJSONFile(s.path, entries)
+}
+
+func (s *FileStore) GetPath() string {
+	return s.path
+}
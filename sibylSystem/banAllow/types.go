@@ -0,0 +1,122 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banAllow
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+)
+
+// Allowlist keeps the local overrides of the sibyl's verdicts; users in
+// the allowlist are allowed in a chat (or in all chats) even if they are
+// banned in sibyl. it also keeps the last seen sibyl status of the users,
+// so the community can be notified when it changes.
+type Allowlist struct {
+	mut     *sync.RWMutex
+	store   Store
+	entries map[entryKey]*Entry
+
+	// users indexes the entries by their user-id and chat-id, so the
+	// users which aren't allowlisted are skipped quickly by Observe.
+	users map[int64]map[int64]*Entry
+
+	// dirty is true if the status of any entry has been changed since
+	// the last time they were saved.
+	dirty bool
+
+	onStatusChange func(*StatusChange)
+}
+
+type AllowlistConfig struct {
+	// Store is the place where the entries are persisted. if nil, the
+	// entries will only be kept in the memory.
+	Store Store
+}
+
+// Store is a persistent storage for the entries of the allowlist.
+type Store interface {
+	// Load loads the saved entries; it should return (nil, nil) if
+	// nothing has been saved yet.
+	Load() ([]*Entry, error)
+
+	// Save saves the given entries, replacing the previous ones.
+	Save(entries []*Entry) error
+}
+
+// MemoryStore is a Store which keeps the entries in the memory only.
+type MemoryStore struct {
+	mut     *sync.Mutex
+	entries []*Entry
+}
+
+// FileStore is a Store which keeps the entries in a json file.
+type FileStore struct {
+	mut  *sync.Mutex
+	path string
+}
+
+// Entry allows a user in a chat, or in all chats if ChatId is
+// GlobalChatId.
+type Entry struct {
+	ChatId  int64     `json:"chat_id"`
+	UserId  int64     `json:"user_id"`
+	Reason  string    `json:"reason"`
+	AddedBy int64     `json:"added_by"`
+	AddedAt time.Time `json:"added_at"`
+
+	// ExpiresAt is the time the entry expires at; the entry never expires
+	// if it's zero.
+	ExpiresAt time.Time `json:"expires_at"`
+
+	// Status is the last seen sibyl status of the user; it's nil if the
+	// user hasn't been seen since the entry was added. it's saved lazily,
+	// see Allowlist.Flush.
+	Status *Status `json:"status,omitempty"`
+}
+
+// Status is the sibyl status of a user at a specific time.
+type Status struct {
+	Banned           bool                         `json:"banned"`
+	Reason           string                       `json:"reason"`
+	CrimeCoefficient sibylSystem.CrimeCoefficient `json:"crime_coefficient"`
+	BanFlags         []sibylSystem.BanFlag        `json:"ban_flags"`
+
+	// CheckedAt is the time the status has been seen for the first time;
+	// it isn't updated while the status stays the same.
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// StatusChange is a change in the sibyl status of an allowlisted user.
+type StatusChange struct {
+	Entry    *Entry
+	Previous *Status
+	Current  *Status
+}
+
+// ReconciliationReport is the result of checking all of the allowlisted
+// users against sibyl.
+type ReconciliationReport struct {
+	// Checked is the count of the users checked successfully.
+	Checked int
+
+	// Changes are the users whose status has changed since the last time
+	// they were seen.
+	Changes []*StatusChange
+
+	// Expired are the entries which have been removed, since they were
+	// expired.
+	Expired []*Entry
+
+	// Failed contains the errors of fetching the info of users.
+	Failed map[int64]error
+}
+
+type entryKey struct {
+	chatId int64
+	userId int64
+}
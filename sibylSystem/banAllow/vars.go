@@ -0,0 +1,14 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banAllow
+
+import "errors"
+
+var (
+	ErrNoClient     = errors.New("a sibyl client is required for reconciling the allowlist")
+	ErrInvalidEntry = errors.New("allowlist entry has no valid user id")
+	ErrNoReason     = errors.New("allowlist entry has no reason")
+)
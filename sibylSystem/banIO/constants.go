@@ -0,0 +1,51 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banIO
+
+const (
+	FormatUnknown Format = iota
+	FormatCSV
+	FormatJSON
+	FormatNDJSON
+	FormatMarkdown
+)
+
+// exportable columns of the ban records.
+const (
+	ColumnUserId           Column = "user_id"
+	ColumnBanned           Column = "banned"
+	ColumnReason           Column = "reason"
+	ColumnMessage          Column = "message"
+	ColumnFlags            Column = "flags"
+	ColumnCrimeCoefficient Column = "crime_coefficient"
+	ColumnBannedBy         Column = "banned_by"
+	ColumnDate             Column = "date"
+	ColumnTargetType       Column = "target_type"
+	ColumnSrcUrl           Column = "source_url"
+)
+
+const (
+	// PlanActionBan means the user isn't banned yet and will be banned.
+	PlanActionBan PlanAction = iota + 1
+	// PlanActionUpdate means the user is already banned with another
+	// reason, and the ban will be updated.
+	PlanActionUpdate
+	// PlanActionSkip means the user is already banned with the same
+	// reason, nothing will be done.
+	PlanActionSkip
+	// PlanActionError means the current state of the user couldn't be
+	// fetched; the record won't be applied.
+	PlanActionError
+)
+
+// record columns.
+const (
+	columnUserId     = "user_id"
+	columnReason     = "reason"
+	columnMessage    = "message"
+	columnSrcUrl     = "source_url"
+	columnEntityType = "entity_type"
+)
@@ -0,0 +1,504 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banIO
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	urlLib "net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+)
+
+// DetectFormat detects the format of a file using its extension.
+func DetectFormat(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV
+	case ".json":
+		return FormatJSON
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON
+	case ".md", ".markdown":
+		return FormatMarkdown
+	default:
+		return FormatUnknown
+	}
+}
+
+// ReadFile reads the ban records from the file at the given path, the
+// format is detected using the extension of the file.
+func ReadFile(path string) ([]*ImportRecord, error) {
+	format := DetectFormat(path)
+	if format == FormatUnknown {
+		return nil, ErrUnknownFormat
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadRecords(f, format)
+}
+
+// ReadRecords reads the ban records from r. only syntax errors are returned
+// as error; invalid values are reported by ValidateRecords.
+func ReadRecords(r io.Reader, format Format) ([]*ImportRecord, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(r)
+	case FormatJSON:
+		return readJSON(r)
+	case FormatNDJSON:
+		return readNDJSON(r)
+	case FormatMarkdown:
+		return nil, ErrUnsupportedFormat
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// NewBanExporter returns a new exporter which writes the given columns of
+// the bans to w in the given format. if no columns are given, DefaultColumns
+// will be used.
+func NewBanExporter(w io.Writer, format Format, columns ...Column) (*BanExporter, error) {
+	if len(columns) == 0 {
+		columns = DefaultColumns
+	}
+
+	for _, current := range columns {
+		if !knownColumns[current] {
+			return nil, ErrUnknownColumn
+		}
+	}
+
+	e := &BanExporter{
+		writer:  bufio.NewWriter(w),
+		format:  format,
+		columns: columns,
+	}
+
+	switch format {
+	case FormatCSV:
+		e.csv = csv.NewWriter(e.writer)
+		return e, e.csv.Write(columnNames(columns))
+	case FormatJSON:
+		return e, e.writer.WriteByte('[')
+	case FormatNDJSON:
+		return e, nil
+	case FormatMarkdown:
+		return e, e.writeMarkdownHeader(columnNames(columns))
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// ExportBans writes all of the bans of the result to w.
+func ExportBans(w io.Writer, result *sibylSystem.GetBansResult, format Format, columns ...Column) error {
+	e, err := NewBanExporter(w, format, columns...)
+	if err != nil {
+		return err
+	}
+
+	if result != nil {
+		for i := range result.Users {
+			err = e.Write(&result.Users[i])
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return e.Close()
+}
+
+// ExportBansFromClient streams the whole ban list from the server directly
+// to w, without holding the ban list in the memory.
+func ExportBansFromClient(
+	ctx context.Context,
+	w io.Writer,
+	client sibylSystem.SibylClient,
+	format Format,
+	columns ...Column,
+) error {
+	e, err := NewBanExporter(w, format, columns...)
+	if err != nil {
+		return err
+	}
+
+	var writeErr error
+	err = client.StreamBannedUsers(ctx, func(info *sibylSystem.BanInfo) bool {
+		writeErr = e.Write(info)
+		return writeErr == nil
+	})
+	if err != nil {
+		return err
+	} else if writeErr != nil {
+		return writeErr
+	}
+
+	return e.Close()
+}
+
+// ExportStats writes the stats to w; csv and markdown outputs are tables of
+// name and value, json and ndjson outputs are a single object.
+func ExportStats(w io.Writer, stats *sibylSystem.GetStatsResult, format Format) error {
+	if stats == nil {
+		stats = new(sibylSystem.GetStatsResult)
+	}
+
+	writer := bufio.NewWriter(w)
+	rows := GetStatsRows(stats)
+
+	var err error
+	switch format {
+	case FormatCSV:
+		csvWriter := csv.NewWriter(writer)
+		_ = csvWriter.Write([]string{"name", "value"})
+		for _, current := range rows {
+			_ = csvWriter.Write([]string{current.Name, strconv.FormatInt(current.Value, 10)})
+		}
+		csvWriter.Flush()
+		err = csvWriter.Error()
+	case FormatJSON, FormatNDJSON:
+		var b []byte
+		b, err = json.Marshal(stats)
+		if err == nil {
+			_, _ = writer.Write(b)
+			err = writer.WriteByte('\n')
+		}
+	case FormatMarkdown:
+		_, _ = writer.WriteString("| name | value |\n| --- | ---: |\n")
+		for _, current := range rows {
+			_, err = writer.WriteString("| " + escapeMarkdownCell(current.Name) + " | " +
+				strconv.FormatInt(current.Value, 10) + " |\n")
+		}
+	default:
+		return ErrUnknownFormat
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
+
+// GetStatsRows returns the stats as rows of name and value, in the same
+// order as the fields of GetStatsResult.
+func GetStatsRows(stats *sibylSystem.GetStatsResult) []*StatsRow {
+	return []*StatsRow{
+		{"banned_count", stats.BannedCount},
+		{"trolling_ban_count", stats.TrollingBanCount},
+		{"spam_ban_count", stats.SpamBanCount},
+		{"evade_ban_count", stats.EvadeBanCount},
+		{"custom_ban_count", stats.CustomBanCount},
+		{"psycho_hazard_ban_count", stats.PsychoHazardBanCount},
+		{"mal_imp_ban_count", stats.MalImpBanCount},
+		{"nsfw_ban_count", stats.NSFWBanCount},
+		{"spam_bot_ban_count", stats.SpamBotBanCount},
+		{"raid_ban_count", stats.RaidBanCount},
+		{"mass_add_ban_count", stats.MassAddBanCount},
+		{"cloudy_count", stats.CloudyCount},
+		{"token_count", stats.TokenCount},
+		{"inspectors_count", stats.InspectorsCount},
+		{"enforces_count", stats.EnforcesCount},
+	}
+}
+
+func columnNames(columns []Column) []string {
+	names := make([]string, len(columns))
+	for i, current := range columns {
+		names[i] = string(current)
+	}
+
+	return names
+}
+
+// escapeMarkdownCell escapes the value, so it can be put in a cell of a
+// markdown table without breaking it.
+func escapeMarkdownCell(value string) string {
+	value = strings.ReplaceAll(value, "\\", "\\\\")
+	value = strings.ReplaceAll(value, "|", "\\|")
+	value = strings.ReplaceAll(value, "\r", "")
+	return strings.ReplaceAll(value, "\n", "<br>")
+}
+
+// ValidateRecords checks the records against the rules of sibyl's ban
+// requests, and separates the valid records from the invalid ones.
+func ValidateRecords(records []*ImportRecord) ([]*ImportRecord, []*RecordError) {
+	var valid []*ImportRecord
+	var invalid []*RecordError
+	seen := make(map[int64]bool, len(records))
+	for _, current := range records {
+		err := validateRecord(current)
+		if err == nil && seen[current.UserId] {
+			err = ErrDuplicateRecord
+		}
+
+		if err != nil {
+			invalid = append(invalid, &RecordError{
+				Record: current,
+				Err:    err,
+			})
+			continue
+		}
+
+		seen[current.UserId] = true
+		valid = append(valid, current)
+	}
+
+	return valid, invalid
+}
+
+// Preview validates the records and compares them with the current state
+// of the users on sibyl, without changing anything. the returned plan can
+// be applied later using its Apply method.
+func Preview(ctx context.Context, client sibylSystem.SibylClient, records []*ImportRecord) (*ImportPlan, error) {
+	valid, invalid := ValidateRecords(records)
+	plan := &ImportPlan{
+		Invalid: invalid,
+	}
+
+	ids := make([]int64, len(valid))
+	for i, current := range valid {
+		ids[i] = current.UserId
+	}
+
+	infos, err := client.GetInfoMany(ctx, ids, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, current := range valid {
+		entry := &PlanEntry{
+			Record:  current,
+			Current: infos.Results[current.UserId],
+			Err:     infos.Errors[current.UserId],
+		}
+
+		switch {
+		case entry.Err != nil:
+			entry.Action = PlanActionError
+		case entry.Current == nil || !entry.Current.Banned:
+			entry.Action = PlanActionBan
+		case entry.Current.Reason == current.Reason:
+			entry.Action = PlanActionSkip
+		default:
+			entry.Action = PlanActionUpdate
+		}
+
+		plan.Entries = append(plan.Entries, entry)
+	}
+
+	return plan, nil
+}
+
+func readCSV(r io.Reader) ([]*ImportRecord, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]string, len(header))
+	hasUserId := false
+	for i, current := range header {
+		columns[i] = columnAliases[strings.ToLower(strings.TrimSpace(current))]
+		hasUserId = hasUserId || columns[i] == columnUserId
+	}
+
+	if !hasUserId {
+		return nil, ErrNoUserIdColumn
+	}
+
+	var records []*ImportRecord
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		values := make(map[string]string, len(row))
+		for i, current := range row {
+			if i < len(columns) && columns[i] != "" {
+				values[columns[i]] = current
+			}
+		}
+
+		records = append(records, toImportRecord(values, line))
+	}
+
+	return records, nil
+}
+
+// readJSON reads either an array of records, or an object containing the
+// records in its "users" field (same as the result of getBans).
+func readJSON(r io.Reader) ([]*ImportRecord, error) {
+	reader := bufio.NewReader(r)
+	first, err := peekNonSpace(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var rawRecords []map[string]json.RawMessage
+	if first == '{' {
+		container := new(struct {
+			Users []map[string]json.RawMessage `json:"users"`
+		})
+		err = json.NewDecoder(reader).Decode(container)
+		rawRecords = container.Users
+	} else {
+		err = json.NewDecoder(reader).Decode(&rawRecords)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*ImportRecord, len(rawRecords))
+	for i, current := range rawRecords {
+		records[i] = toImportRecord(toStringValues(current), i+1)
+	}
+
+	return records, nil
+}
+
+func readNDJSON(r io.Reader) ([]*ImportRecord, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var records []*ImportRecord
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var raw map[string]json.RawMessage
+		err := json.Unmarshal([]byte(text), &raw)
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, toImportRecord(toStringValues(raw), line))
+	}
+
+	return records, scanner.Err()
+}
+
+func peekNonSpace(reader *bufio.Reader) (byte, error) {
+	for {
+		b, err := reader.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			_, _ = reader.ReadByte()
+		default:
+			return b[0], nil
+		}
+	}
+}
+
+// toStringValues converts the raw json values to strings; string values
+// are unquoted, and other values (e.g. numbers) are kept as they are.
+func toStringValues(raw map[string]json.RawMessage) map[string]string {
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		column := columnAliases[strings.ToLower(key)]
+		if column == "" {
+			continue
+		}
+
+		var str string
+		if json.Unmarshal(value, &str) != nil {
+			str = string(value)
+			if str == "null" {
+				str = ""
+			}
+		}
+		values[column] = str
+	}
+
+	return values
+}
+
+// toImportRecord converts the values to a record; invalid values are kept
+// in the record as parse errors, to be reported by ValidateRecords.
+func toImportRecord(values map[string]string, line int) *ImportRecord {
+	record := &ImportRecord{
+		Reason:  strings.TrimSpace(values[columnReason]),
+		Message: strings.TrimSpace(values[columnMessage]),
+		SrcUrl:  strings.TrimSpace(values[columnSrcUrl]),
+		Line:    line,
+	}
+
+	var err error
+	record.UserId, err = strconv.ParseInt(strings.TrimSpace(values[columnUserId]), 10, 64)
+	if err != nil {
+		record.parseErr = ErrInvalidUserId
+	}
+
+	// an empty entity type means a user.
+	record.TargetType = sibylSystem.EntityTypeUser
+	if entityType := strings.TrimSpace(values[columnEntityType]); entityType != "" {
+		record.TargetType, err = sibylSystem.ParseEntityType(entityType)
+		if err != nil && record.parseErr == nil {
+			record.parseErr = err
+		}
+	}
+
+	return record
+}
+
+func validateRecord(record *ImportRecord) error {
+	if record.parseErr != nil {
+		return record.parseErr
+	}
+
+	if record.UserId == 0 {
+		return ErrInvalidUserId
+	}
+
+	// the same checks as Ban, so invalid reasons show up in the preview
+	// instead of failing when the plan gets applied.
+	if err := sibylSystem.ValidateReason(record.Reason); err != nil {
+		return err
+	}
+
+	if !record.TargetType.IsValid() {
+		return sibylSystem.ErrInvalidEntityType
+	}
+
+	if record.SrcUrl != "" {
+		u, err := urlLib.Parse(record.SrcUrl)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return ErrInvalidSrcUrl
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,299 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banIO
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+)
+
+// Apply bans the users of the plan which are not banned yet (or are banned
+// with another reason), using the bulk ban path of the client.
+func (p *ImportPlan) Apply(
+	ctx context.Context,
+	client sibylSystem.SibylClient,
+	opts *sibylSystem.BulkOptions,
+) (*sibylSystem.BanManyReport, error) {
+	return client.BanMany(ctx, p.GetTargets(), opts)
+}
+
+// GetTargets returns the ban targets of the entries which will be applied.
+func (p *ImportPlan) GetTargets() []*sibylSystem.BanTarget {
+	var targets []*sibylSystem.BanTarget
+	for _, current := range p.Entries {
+		if !current.Action.IsApplied() {
+			continue
+		}
+
+		targets = append(targets, current.Record.ToBanTarget())
+	}
+
+	return targets
+}
+
+// Count returns the count of the entries with the given action.
+func (p *ImportPlan) Count(action PlanAction) int {
+	count := 0
+	for _, current := range p.Entries {
+		if current.Action == action {
+			count++
+		}
+	}
+
+	return count
+}
+
+//---------------------------------------------------------
+
+// ToBanTarget converts the record to a target of the bulk ban.
+func (r *ImportRecord) ToBanTarget() *sibylSystem.BanTarget {
+	return &sibylSystem.BanTarget{
+		UserId: r.UserId,
+		Reason: r.Reason,
+		Config: &sibylSystem.BanConfig{
+			Message:    r.Message,
+			SrcUrl:     r.SrcUrl,
+			TargetType: r.TargetType,
+		},
+	}
+}
+
+//---------------------------------------------------------
+
+func (e *RecordError) Error() string {
+	return "line " + strconv.Itoa(e.Record.Line) + ": " + e.Err.Error()
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+//---------------------------------------------------------
+
+// IsApplied returns true if the entries with this action will be sent
+// to sibyl when the plan gets applied.
+func (a PlanAction) IsApplied() bool {
+	return a == PlanActionBan || a == PlanActionUpdate
+}
+
+func (a PlanAction) String() string {
+	switch a {
+	case PlanActionBan:
+		return "ban"
+	case PlanActionUpdate:
+		return "update"
+	case PlanActionSkip:
+		return "skip"
+	case PlanActionError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+//---------------------------------------------------------
+
+func (f Format) String() string {
+	switch f {
+	case FormatCSV:
+		return "csv"
+	case FormatJSON:
+		return "json"
+	case FormatNDJSON:
+		return "ndjson"
+	case FormatMarkdown:
+		return "markdown"
+	default:
+		return "unknown"
+	}
+}
+
+//---------------------------------------------------------
+
+// Write writes a single ban record.
+func (e *BanExporter) Write(info *sibylSystem.BanInfo) error {
+	if e.closed {
+		return ErrExporterClosed
+	}
+
+	var err error
+	switch e.format {
+	case FormatCSV:
+		err = e.csv.Write(e.getTextValues(info))
+	case FormatJSON:
+		if e.count != 0 {
+			_ = e.writer.WriteByte(',')
+		}
+		err = e.writeJSONObject(info)
+	case FormatNDJSON:
+		err = e.writeJSONObject(info)
+		if err == nil {
+			err = e.writer.WriteByte('\n')
+		}
+	case FormatMarkdown:
+		err = e.writeMarkdownRow(e.getTextValues(info))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	e.count++
+	return nil
+}
+
+// Close completes the output and flushes it to the underlying writer.
+// it doesn't close the underlying writer itself.
+func (e *BanExporter) Close() error {
+	if e.closed {
+		return ErrExporterClosed
+	}
+	e.closed = true
+
+	switch e.format {
+	case FormatCSV:
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	case FormatJSON:
+		if _, err := e.writer.WriteString("]\n"); err != nil {
+			return err
+		}
+	}
+
+	return e.writer.Flush()
+}
+
+// Count returns the count of records written so far.
+func (e *BanExporter) Count() int {
+	return e.count
+}
+
+func (e *BanExporter) getTextValues(info *sibylSystem.BanInfo) []string {
+	values := make([]string, len(e.columns))
+	for i, current := range e.columns {
+		values[i] = current.GetText(info)
+	}
+
+	return values
+}
+
+// writeJSONObject writes the selected columns of the ban as a json object,
+// keeping the order of the columns.
+func (e *BanExporter) writeJSONObject(info *sibylSystem.BanInfo) error {
+	_ = e.writer.WriteByte('{')
+	for i, current := range e.columns {
+		if i != 0 {
+			_ = e.writer.WriteByte(',')
+		}
+
+		b, err := json.Marshal(current.GetValue(info))
+		if err != nil {
+			return err
+		}
+
+		_, _ = e.writer.WriteString(strconv.Quote(string(current)) + ":")
+		_, _ = e.writer.Write(b)
+	}
+
+	return e.writer.WriteByte('}')
+}
+
+func (e *BanExporter) writeMarkdownHeader(names []string) error {
+	err := e.writeMarkdownRow(names)
+	if err != nil {
+		return err
+	}
+
+	separators := make([]string, len(names))
+	for i := range separators {
+		separators[i] = "---"
+	}
+
+	_, err = e.writer.WriteString("| " + strings.Join(separators, " | ") + " |\n")
+	return err
+}
+
+func (e *BanExporter) writeMarkdownRow(values []string) error {
+	for i := range values {
+		values[i] = escapeMarkdownCell(values[i])
+	}
+
+	_, err := e.writer.WriteString("| " + strings.Join(values, " | ") + " |\n")
+	return err
+}
+
+//---------------------------------------------------------
+
+// GetValue returns the value of the column for the ban, as it should be
+// put in a json output.
+func (c Column) GetValue(info *sibylSystem.BanInfo) interface{} {
+	switch c {
+	case ColumnUserId:
+		return info.UserId
+	case ColumnBanned:
+		return info.Banned
+	case ColumnReason:
+		return info.Reason
+	case ColumnMessage:
+		return info.Message
+	case ColumnFlags:
+		if info.BanFlags == nil {
+			return []string{}
+		}
+		return info.BanFlags
+	case ColumnCrimeCoefficient:
+		return info.CrimeCoefficient
+	case ColumnBannedBy:
+		return info.BannedBy
+	case ColumnDate:
+		return info.Date
+	case ColumnTargetType:
+		return info.TargetType
+	case ColumnSrcUrl:
+		return info.BanSourceUrl
+	default:
+		return nil
+	}
+}
+
+// GetText returns the value of the column for the ban, as it should be
+// put in a text output (csv or markdown).
+func (c Column) GetText(info *sibylSystem.BanInfo) string {
+	switch c {
+	case ColumnUserId:
+		return strconv.FormatInt(info.UserId, 10)
+	case ColumnBanned:
+		return strconv.FormatBool(info.Banned)
+	case ColumnReason:
+		return info.Reason
+	case ColumnMessage:
+		return info.Message
+	case ColumnFlags:
+		flags := make([]string, len(info.BanFlags))
+		for i, current := range info.BanFlags {
+			flags[i] = string(current)
+		}
+		return strings.Join(flags, ",")
+	case ColumnCrimeCoefficient:
+		return info.CrimeCoefficient.String()
+	case ColumnBannedBy:
+		return strconv.FormatInt(info.BannedBy, 10)
+	case ColumnDate:
+		return info.Date.String()
+	case ColumnTargetType:
+		return sibylSystem.GetEntityTypeName(info.TargetType)
+	case ColumnSrcUrl:
+		return info.BanSourceUrl
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,72 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banIO
+
+import (
+	"bufio"
+	"encoding/csv"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+)
+
+type Format int
+type PlanAction int
+type Column string
+
+// BanExporter writes ban records to a writer one by one, so exporting a
+// huge ban list doesn't need it to be held in the memory. Close must be
+// called after the last record, to complete the output.
+type BanExporter struct {
+	writer  *bufio.Writer
+	csv     *csv.Writer
+	format  Format
+	columns []Column
+	count   int
+	closed  bool
+}
+
+// StatsRow is a single row of the exported stats.
+type StatsRow struct {
+	Name  string
+	Value int64
+}
+
+// ImportRecord is a single ban record read from an import file.
+type ImportRecord struct {
+	UserId     int64
+	Reason     string
+	Message    string
+	SrcUrl     string
+	TargetType sibylSystem.EntityType
+
+	// Line is the line (or the index, for json arrays) of the record in
+	// the file, starting from 1.
+	Line int
+
+	parseErr error
+}
+
+// RecordError is the validation error of a single record.
+type RecordError struct {
+	Record *ImportRecord
+	Err    error
+}
+
+// ImportPlan is the result of a dry-run import; it shows what will happen
+// to each one of the records if the plan gets applied.
+type ImportPlan struct {
+	Entries []*PlanEntry
+	Invalid []*RecordError
+}
+
+// PlanEntry is a single valid record of the plan, along with the current
+// state of the user on sibyl.
+type PlanEntry struct {
+	Record  *ImportRecord
+	Current *sibylSystem.GetInfoResult
+	Action  PlanAction
+	Err     error
+}
@@ -0,0 +1,67 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banIO
+
+import "errors"
+
+// error variables
+var (
+	ErrUnknownFormat     = errors.New("unknown ban list format")
+	ErrNoUserIdColumn    = errors.New("csv header doesn't contain a user id column")
+	ErrInvalidUserId     = errors.New("invalid user id")
+	ErrInvalidSrcUrl     = errors.New("source url should be an absolute http(s) url")
+	ErrDuplicateRecord   = errors.New("user id is duplicated in the file")
+	ErrUnsupportedFormat = errors.New("format is not supported for this operation")
+	ErrUnknownColumn     = errors.New("unknown column")
+	ErrExporterClosed    = errors.New("exporter is already closed")
+)
+
+// DefaultColumns are the columns exported when no columns are specified.
+var DefaultColumns = []Column{
+	ColumnUserId,
+	ColumnReason,
+	ColumnFlags,
+	ColumnCrimeCoefficient,
+	ColumnBannedBy,
+	ColumnDate,
+	ColumnTargetType,
+	ColumnSrcUrl,
+}
+
+var knownColumns = map[Column]bool{
+	ColumnUserId:           true,
+	ColumnBanned:           true,
+	ColumnReason:           true,
+	ColumnMessage:          true,
+	ColumnFlags:            true,
+	ColumnCrimeCoefficient: true,
+	ColumnBannedBy:         true,
+	ColumnDate:             true,
+	ColumnTargetType:       true,
+	ColumnSrcUrl:           true,
+}
+
+// columnAliases maps the accepted csv column names (and json keys) to the
+// fields of the records, so files exported by other bots can be imported
+// without being edited.
+var columnAliases = map[string]string{
+	"user_id":        columnUserId,
+	"user-id":        columnUserId,
+	"userid":         columnUserId,
+	"id":             columnUserId,
+	"reason":         columnReason,
+	"message":        columnMessage,
+	"msg":            columnMessage,
+	"source_url":     columnSrcUrl,
+	"src_url":        columnSrcUrl,
+	"srcurl":         columnSrcUrl,
+	"src":            columnSrcUrl,
+	"ban_source_url": columnSrcUrl,
+	"entity_type":    columnEntityType,
+	"entity-type":    columnEntityType,
+	"target_type":    columnEntityType,
+	"type":           columnEntityType,
+}
@@ -0,0 +1,49 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banIndex
+
+// index file format constants.
+const (
+	indexMagic   = "SBIX"
+	indexVersion = 1
+	headerSize   = 32
+
+	idSize          = 8
+	flagSize        = 4
+	coefficientSize = 2
+)
+
+// bloom filter constants; 10 bits per entry with 7 hashes gives a false
+// positive rate of nearly 1%.
+const (
+	bloomBitsPerEntry = 10
+	bloomHashes       = 7
+
+	// maxBloomHashes is the max count of hashes accepted when loading an
+	// index, so a corrupted header can't make the lookups too slow.
+	maxBloomHashes = 32
+)
+
+const (
+	// MaxStoredCoefficient is the biggest crime coefficient which can be
+	// stored in the index; bigger values are clamped to it.
+	MaxStoredCoefficient = 0xFFFF
+)
+
+// flag bits; FlagUnknown is used for the flags the index doesn't know about.
+const (
+	FlagTrolling FlagSet = 1 << iota
+	FlagSpam
+	FlagEvade
+	FlagCustom
+	FlagPsychoHazard
+	FlagMalImp
+	FlagNSFW
+	FlagRaid
+	FlagSpamBot
+	FlagMassAdd
+	FlagUnknown FlagSet = 1 << 31
+)
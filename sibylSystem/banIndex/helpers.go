@@ -0,0 +1,167 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banIndex
+
+import (
+	"encoding/binary"
+	"os"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+)
+
+// NewBuilder returns a new empty index builder.
+func NewBuilder() *Builder {
+	return &Builder{
+		entries: make(map[int64]Entry),
+	}
+}
+
+// Build builds a new in-memory index from the given ban list.
+func Build(result *sibylSystem.GetBansResult) *Index {
+	b := NewBuilder()
+	if result != nil {
+		for i := range result.Users {
+			b.Add(&result.Users[i])
+		}
+	}
+
+	return b.Build()
+}
+
+// Load loads an index from its binary representation. the index keeps
+// using the given slice, so it shouldn't be modified afterwards.
+func Load(data []byte) (*Index, error) {
+	if len(data) < headerSize {
+		return nil, ErrCorruptedIndex
+	}
+
+	if string(data[:4]) != indexMagic {
+		return nil, ErrInvalidMagic
+	}
+
+	if binary.LittleEndian.Uint16(data[4:]) != indexVersion {
+		return nil, ErrInvalidVersion
+	}
+
+	count := binary.LittleEndian.Uint64(data[8:])
+	bloomBits := binary.LittleEndian.Uint64(data[16:])
+	hashes := binary.LittleEndian.Uint32(data[24:])
+
+	// make sure none of the computations below can overflow.
+	if count > uint64(len(data)) || bloomBits > uint64(len(data))*8 || bloomBits%64 != 0 {
+		return nil, ErrCorruptedIndex
+	}
+
+	// the bloom filter is used for all of the lookups of a non-empty index.
+	if count != 0 && (bloomBits == 0 || hashes == 0 || hashes > maxBloomHashes) {
+		return nil, ErrCorruptedIndex
+	}
+
+	index := newIndexLayout(int(count), bloomBits, hashes)
+	if len(data) != index.bloomOffset+int(bloomBits/8) {
+		return nil, ErrCorruptedIndex
+	}
+
+	index.data = data
+	return index, nil
+}
+
+// OpenFile opens the index file at the given path. on the platforms which
+// support it, the file is memory-mapped instead of being read into the
+// memory; the index should be closed after use.
+func OpenFile(path string) (*Index, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	data, closer, err := mapFile(f)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := Load(data)
+	if err != nil {
+		_ = closer()
+		return nil, err
+	}
+
+	index.closer = closer
+	return index, nil
+}
+
+// ParseFlags converts the given flags to a FlagSet. flags unknown
+// to the index are stored as FlagUnknown.
+func ParseFlags(flags []sibylSystem.BanFlag) FlagSet {
+	var set FlagSet
+	for _, current := range flags {
+		bit, ok := flagBits[current]
+		if !ok {
+			bit = FlagUnknown
+		}
+		set |= bit
+	}
+
+	return set
+}
+
+// newIndexLayout returns an index without data, with all of its section
+// offsets computed from the count of entries and bloom filter parameters.
+func newIndexLayout(count int, bloomBits uint64, hashes uint32) *Index {
+	index := &Index{
+		count:       count,
+		bloomBits:   bloomBits,
+		bloomHashes: hashes,
+	}
+
+	index.flagsOffset = headerSize + count*idSize
+	index.coefficientOffset = index.flagsOffset + count*flagSize
+	index.bloomOffset = align8(index.coefficientOffset + count*coefficientSize)
+
+	return index
+}
+
+func getBloomBits(count int) uint64 {
+	bits := uint64(count) * bloomBitsPerEntry
+	if bits < 64 {
+		bits = 64
+	}
+
+	// round up to a multiple of 64, so the bloom section stays aligned.
+	return (bits + 63) &^ 63
+}
+
+// bloomHash returns two independent hashes of the user-id, used for
+// double hashing in the bloom filter.
+func bloomHash(userId int64) (uint64, uint64) {
+	h1 := splitMix64(uint64(userId))
+	h2 := splitMix64(h1) | 1
+	return h1, h2
+}
+
+func splitMix64(x uint64) uint64 {
+	x += 0x9E3779B97F4A7C15
+	x = (x ^ (x >> 30)) * 0xBF58476D1CE4E5B9
+	x = (x ^ (x >> 27)) * 0x94D049BB133111EB
+	return x ^ (x >> 31)
+}
+
+func align8(value int) int {
+	return (value + 7) &^ 7
+}
+
+func clampCoefficient(value sibylSystem.CrimeCoefficient) uint16 {
+	if value < 0 {
+		return 0
+	}
+
+	if value > MaxStoredCoefficient {
+		return MaxStoredCoefficient
+	}
+
+	return uint16(value)
+}
@@ -0,0 +1,257 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banIndex
+
+import (
+	"encoding/binary"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+)
+
+// Add adds the given ban to the builder. bans which are not active
+// are ignored; adding the same user-id twice replaces the old entry.
+func (b *Builder) Add(info *sibylSystem.BanInfo) {
+	if info == nil || !info.Banned {
+		return
+	}
+
+	b.entries[info.UserId] = Entry{
+		UserId:           info.UserId,
+		Flags:            ParseFlags(info.BanFlags),
+		CrimeCoefficient: info.CrimeCoefficient,
+	}
+}
+
+// AddEntry adds the given entry to the builder directly.
+func (b *Builder) AddEntry(entry Entry) {
+	b.entries[entry.UserId] = entry
+}
+
+// Length returns the count of entries added to the builder.
+func (b *Builder) Length() int {
+	return len(b.entries)
+}
+
+// Build builds the index from the added entries. the builder can still
+// be used afterwards.
+func (b *Builder) Build() *Index {
+	ids := make([]int64, 0, len(b.entries))
+	for userId := range b.entries {
+		ids = append(ids, userId)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	bloomBits := getBloomBits(len(ids))
+	index := newIndexLayout(len(ids), bloomBits, bloomHashes)
+	data := make([]byte, index.bloomOffset+int(bloomBits/8))
+
+	copy(data, indexMagic)
+	binary.LittleEndian.PutUint16(data[4:], indexVersion)
+	binary.LittleEndian.PutUint64(data[8:], uint64(len(ids)))
+	binary.LittleEndian.PutUint64(data[16:], bloomBits)
+	binary.LittleEndian.PutUint32(data[24:], bloomHashes)
+
+	index.data = data
+	for i, userId := range ids {
+		entry := b.entries[userId]
+		binary.LittleEndian.PutUint64(data[headerSize+i*idSize:], uint64(userId))
+		binary.LittleEndian.PutUint32(data[index.flagsOffset+i*flagSize:], uint32(entry.Flags))
+		binary.LittleEndian.PutUint16(
+			data[index.coefficientOffset+i*coefficientSize:],
+			clampCoefficient(entry.CrimeCoefficient),
+		)
+		index.addToBloom(userId)
+	}
+
+	return index
+}
+
+//---------------------------------------------------------
+
+// Lookup returns the entry of the given user-id, using a binary search
+// over the sorted ids.
+func (i *Index) Lookup(userId int64) (*Entry, bool) {
+	if !i.MightBeBanned(userId) {
+		return nil, false
+	}
+
+	pos := sort.Search(i.count, func(n int) bool {
+		return i.getId(n) >= userId
+	})
+	if pos >= i.count || i.getId(pos) != userId {
+		return nil, false
+	}
+
+	return i.getEntry(pos), true
+}
+
+// IsBanned returns true if the user is banned according to the index.
+func (i *Index) IsBanned(userId int64) bool {
+	_, ok := i.Lookup(userId)
+	return ok
+}
+
+// MightBeBanned checks the bloom filter of the index; if it returns
+// false, the user is definitely not banned. if it returns true, the
+// user is very likely to be banned, but Lookup should be used to
+// make sure.
+func (i *Index) MightBeBanned(userId int64) bool {
+	if i.count == 0 || len(i.data) == 0 {
+		return false
+	}
+
+	h1, h2 := bloomHash(userId)
+	for n := uint64(0); n < uint64(i.bloomHashes); n++ {
+		bit := (h1 + n*h2) % i.bloomBits
+		if i.data[i.bloomOffset+int(bit/8)]&(1<<(bit%8)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ForEach calls fn for each entry of the index in ascending order of
+// user-ids, until fn returns false.
+func (i *Index) ForEach(fn func(entry *Entry) bool) {
+	for n := 0; n < i.count; n++ {
+		if !fn(i.getEntry(n)) {
+			return
+		}
+	}
+}
+
+// Length returns the count of entries in the index.
+func (i *Index) Length() int {
+	return i.count
+}
+
+// Size returns the size of the index data in bytes.
+func (i *Index) Size() int {
+	return len(i.data)
+}
+
+// WriteTo writes the binary representation of the index to w.
+func (i *Index) WriteTo(w io.Writer) (int64, error) {
+	if i.data == nil {
+		return 0, ErrIndexClosed
+	}
+
+	n, err := w.Write(i.data)
+	return int64(n), err
+}
+
+// SaveToFile saves the index to the given path, so it can be opened
+// later using OpenFile.
+func (i *Index) SaveToFile(path string) error {
+	if i.data == nil {
+		return ErrIndexClosed
+	}
+
+	return sibylSystem.WriteFileAtomic(path, i.data)
+}
+
+// Close releases the resources of the index (e.g. unmaps the file).
+// the index shouldn't be used after being closed.
+func (i *Index) Close() error {
+	closer := i.closer
+	i.data = nil
+	i.count = 0
+	i.closer = nil
+
+	if closer != nil {
+		return closer()
+	}
+
+	return nil
+}
+
+func (i *Index) getId(n int) int64 {
+	return int64(binary.LittleEndian.Uint64(i.data[headerSize+n*idSize:]))
+}
+
+func (i *Index) getEntry(n int) *Entry {
+	return &Entry{
+		UserId: i.getId(n),
+		Flags:  FlagSet(binary.LittleEndian.Uint32(i.data[i.flagsOffset+n*flagSize:])),
+		CrimeCoefficient: sibylSystem.CrimeCoefficient(binary.LittleEndian.Uint16(
+			i.data[i.coefficientOffset+n*coefficientSize:],
+		)),
+	}
+}
+
+func (i *Index) addToBloom(userId int64) {
+	h1, h2 := bloomHash(userId)
+	for n := uint64(0); n < uint64(i.bloomHashes); n++ {
+		bit := (h1 + n*h2) % i.bloomBits
+		i.data[i.bloomOffset+int(bit/8)] |= 1 << (bit % 8)
+	}
+}
+
+//---------------------------------------------------------
+
+// Has returns true if the given flag exists in the set.
+func (f FlagSet) Has(flag sibylSystem.BanFlag) bool {
+	bit, ok := flagBits[flag]
+	if !ok {
+		return false
+	}
+
+	return f&bit != 0
+}
+
+// HasAny returns true if the set contains at least one of the given flags.
+func (f FlagSet) HasAny(flags FlagSet) bool {
+	return f&flags != 0
+}
+
+// HasAll returns true if the set contains all of the given flags.
+func (f FlagSet) HasAll(flags FlagSet) bool {
+	return f&flags == flags
+}
+
+// HasUnknown returns true if the ban had a flag unknown to the index.
+func (f FlagSet) HasUnknown() bool {
+	return f&FlagUnknown != 0
+}
+
+// ToFlags returns the known flags in the set.
+func (f FlagSet) ToFlags() []sibylSystem.BanFlag {
+	var flags []sibylSystem.BanFlag
+	for n, current := range flagNames {
+		if f&(1<<n) != 0 {
+			flags = append(flags, current)
+		}
+	}
+
+	return flags
+}
+
+// ToStrings returns the names of the known flags in the set.
+func (f FlagSet) ToStrings() []string {
+	var flags []string
+	for _, current := range f.ToFlags() {
+		flags = append(flags, string(current))
+	}
+
+	return flags
+}
+
+func (f FlagSet) String() string {
+	return strings.Join(f.ToStrings(), ", ")
+}
+
+//---------------------------------------------------------
+
+// IsBanned returns true; entries only exist for banned users.
+func (e *Entry) IsBanned() bool {
+	return e != nil
+}
@@ -0,0 +1,26 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package banIndex
+
+import (
+	"io"
+	"os"
+)
+
+// mapFile reads the whole file into the memory, since memory-mapping
+// isn't supported on this platform.
+func mapFile(f *os.File) ([]byte, func() error, error) {
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, func() error {
+		return nil
+	}, nil
+}
@@ -0,0 +1,34 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package banIndex
+
+import (
+	"os"
+	"syscall"
+)
+
+// mapFile memory-maps the given file as read-only.
+func mapFile(f *os.File) ([]byte, func() error, error) {
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if stat.Size() < headerSize {
+		return nil, nil, ErrCorruptedIndex
+	}
+
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(stat.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, func() error {
+		return syscall.Munmap(data)
+	}, nil
+}
@@ -0,0 +1,39 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banIndex
+
+import "github.com/ALiwoto/sibylSystemGo/sibylSystem"
+
+// FlagSet is a bitset of ban flags.
+type FlagSet uint32
+
+// Index is a compact, read-only index of banned users. it only keeps the
+// user-ids (sorted), flags and crime coefficients of the bans, and can be
+// memory-mapped directly from a file.
+type Index struct {
+	data  []byte
+	count int
+
+	flagsOffset       int
+	coefficientOffset int
+	bloomOffset       int
+	bloomBits         uint64
+	bloomHashes       uint32
+
+	closer func() error
+}
+
+// Entry is a single record of the index.
+type Entry struct {
+	UserId           int64
+	Flags            FlagSet
+	CrimeCoefficient sibylSystem.CrimeCoefficient
+}
+
+// Builder collects the bans and builds an Index out of them.
+type Builder struct {
+	entries map[int64]Entry
+}
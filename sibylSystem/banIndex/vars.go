@@ -0,0 +1,48 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banIndex
+
+import (
+	"errors"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+)
+
+// error variables
+var (
+	ErrInvalidMagic   = errors.New("data is not a sibyl ban index")
+	ErrInvalidVersion = errors.New("unsupported ban index version")
+	ErrCorruptedIndex = errors.New("ban index data is corrupted")
+	ErrIndexClosed    = errors.New("ban index is closed")
+)
+
+// flagBits maps each known ban flag to its bit in the FlagSet.
+var flagBits = map[sibylSystem.BanFlag]FlagSet{
+	sibylSystem.BanFlagTrolling:     FlagTrolling,
+	sibylSystem.BanFlagSpam:         FlagSpam,
+	sibylSystem.BanFlagEvade:        FlagEvade,
+	sibylSystem.BanFlagCustom:       FlagCustom,
+	sibylSystem.BanFlagPsychoHazard: FlagPsychoHazard,
+	sibylSystem.BanFlagMalImp:       FlagMalImp,
+	sibylSystem.BanFlagNSFW:         FlagNSFW,
+	sibylSystem.BanFlagRaid:         FlagRaid,
+	sibylSystem.BanFlagSpamBot:      FlagSpamBot,
+	sibylSystem.BanFlagMassAdd:      FlagMassAdd,
+}
+
+// flagNames is the same as flagBits, in the order of bits.
+var flagNames = []sibylSystem.BanFlag{
+	sibylSystem.BanFlagTrolling,
+	sibylSystem.BanFlagSpam,
+	sibylSystem.BanFlagEvade,
+	sibylSystem.BanFlagCustom,
+	sibylSystem.BanFlagPsychoHazard,
+	sibylSystem.BanFlagMalImp,
+	sibylSystem.BanFlagNSFW,
+	sibylSystem.BanFlagRaid,
+	sibylSystem.BanFlagSpamBot,
+	sibylSystem.BanFlagMassAdd,
+}
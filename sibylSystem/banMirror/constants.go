@@ -0,0 +1,26 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banMirror
+
+import "time"
+
+const (
+	// DefaultSyncInterval is the default interval between two syncs of
+	// the mirror.
+	DefaultSyncInterval = 15 * time.Minute
+)
+
+const (
+	// ChangeKindAdded means the user wasn't banned in the previous sync,
+	// but is banned now.
+	ChangeKindAdded ChangeKind = iota + 1
+	// ChangeKindRemoved means the user was banned in the previous sync,
+	// but isn't banned anymore.
+	ChangeKindRemoved
+	// ChangeKindChanged means the user was banned in both syncs, but the
+	// ban information (reason, flags, coefficient, etc) has changed.
+	ChangeKindChanged
+)
@@ -0,0 +1,174 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banMirror
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+)
+
+// NewMirror creates a new mirror of the ban list using the given client.
+// if the config contains a store, the last saved snapshot will be loaded
+// from it, so lookups can be served before the first sync.
+func NewMirror(client sibylSystem.SibylClient, config *MirrorConfig) (*Mirror, error) {
+	if client == nil {
+		return nil, ErrNoClient
+	}
+
+	if config == nil {
+		config = GetDefaultMirrorConfig()
+	}
+
+	m := &Mirror{
+		client:   client,
+		store:    config.Store,
+		interval: config.Interval,
+		mut:      &sync.RWMutex{},
+		syncMut:  &sync.Mutex{},
+		bans:     make(map[int64]*sibylSystem.BanInfo),
+	}
+
+	if m.store == nil {
+		m.store = NewMemoryStore()
+	}
+
+	if m.interval <= 0 {
+		m.interval = DefaultSyncInterval
+	}
+
+	snapshot, err := m.store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	if snapshot != nil {
+		m.bans = toBanMap(snapshot.Users)
+		m.syncedAt = snapshot.SyncedAt
+	}
+
+	return m, nil
+}
+
+// GetDefaultMirrorConfig returns default config of the mirror.
+func GetDefaultMirrorConfig() *MirrorConfig {
+	return &MirrorConfig{
+		Interval: DefaultSyncInterval,
+	}
+}
+
+// NewMemoryStore returns a new store which only keeps the snapshot
+// in the memory.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		mut: &sync.Mutex{},
+	}
+}
+
+// NewFileStore returns a new store which saves the snapshot in the
+// json file at the given path.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{
+		mut:  &sync.Mutex{},
+		path: path,
+	}
+}
+
+// ComputeDiff computes the changes needed to go from the old ban map
+// to the new one.
+func ComputeDiff(oldBans, newBans map[int64]*sibylSystem.BanInfo) *MirrorDiff {
+	diff := new(MirrorDiff)
+	for userId, current := range newBans {
+		previous := oldBans[userId]
+		if previous == nil {
+			diff.Added = append(diff.Added, &BanChange{
+				Kind:    ChangeKindAdded,
+				UserId:  userId,
+				Current: current,
+			})
+			continue
+		}
+
+		if !isSameBan(previous, current) {
+			diff.Changed = append(diff.Changed, &BanChange{
+				Kind:     ChangeKindChanged,
+				UserId:   userId,
+				Previous: previous,
+				Current:  current,
+			})
+		}
+	}
+
+	for userId, previous := range oldBans {
+		if newBans[userId] == nil {
+			diff.Removed = append(diff.Removed, &BanChange{
+				Kind:     ChangeKindRemoved,
+				UserId:   userId,
+				Previous: previous,
+			})
+		}
+	}
+
+	sortChanges(diff.Added)
+	sortChanges(diff.Removed)
+	sortChanges(diff.Changed)
+
+	return diff
+}
+
+func sortChanges(changes []*BanChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].UserId < changes[j].UserId
+	})
+}
+
+func toBanMap(users []sibylSystem.BanInfo) map[int64]*sibylSystem.BanInfo {
+	bans := make(map[int64]*sibylSystem.BanInfo, len(users))
+	for i := range users {
+		if !users[i].Banned {
+			continue
+		}
+
+		bans[users[i].UserId] = &users[i]
+	}
+
+	return bans
+}
+
+func toBanSlice(bans map[int64]*sibylSystem.BanInfo) []sibylSystem.BanInfo {
+	users := make([]sibylSystem.BanInfo, 0, len(bans))
+	for _, current := range bans {
+		users = append(users, *current)
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].UserId < users[j].UserId
+	})
+
+	return users
+}
+
+func isSameBan(b1, b2 *sibylSystem.BanInfo) bool {
+	if b1.Reason != b2.Reason ||
+		b1.Message != b2.Message ||
+		b1.BanSourceUrl != b2.BanSourceUrl ||
+		b1.BannedBy != b2.BannedBy ||
+		b1.CrimeCoefficient != b2.CrimeCoefficient ||
+		!b1.Date.IsSame(b2.Date) ||
+		b1.TargetType != b2.TargetType ||
+		len(b1.BanFlags) != len(b2.BanFlags) {
+		return false
+	}
+
+	for i := range b1.BanFlags {
+		if b1.BanFlags[i] != b2.BanFlags[i] {
+			return false
+		}
+	}
+
+	return true
+}
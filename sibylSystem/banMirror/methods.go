@@ -0,0 +1,359 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banMirror
+
+import (
+	"context"
+	"time"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+)
+
+// Sync fetches the whole ban list from the server, replaces the local copy
+// with it and returns the changes since the previous sync. change events
+// are emitted after the local copy is updated.
+func (m *Mirror) Sync() (*MirrorDiff, error) {
+	return m.SyncWithContext(context.Background())
+}
+
+// SyncWithContext is the same as Sync, but the request can be cancelled
+// using the given context. the ban list is decoded in a streaming manner,
+// so the raw response is never held in the memory.
+func (m *Mirror) SyncWithContext(ctx context.Context) (*MirrorDiff, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	m.syncMut.Lock()
+	defer m.syncMut.Unlock()
+
+	newBans := make(map[int64]*sibylSystem.BanInfo)
+	err := m.client.StreamBannedUsers(ctx, func(info *sibylSystem.BanInfo) bool {
+		if info.Banned {
+			tmp := *info
+			newBans[info.UserId] = &tmp
+		}
+		return true
+	})
+	if err != nil {
+		m.fail(ctx, err)
+		return nil, err
+	}
+
+	now := time.Now()
+
+	m.mut.RLock()
+	diff := ComputeDiff(m.bans, newBans)
+	m.mut.RUnlock()
+	diff.SyncedAt = now
+
+	m.mut.Lock()
+	m.bans = newBans
+	m.syncedAt = now
+	m.lastError = nil
+	m.mut.Unlock()
+
+	err = m.store.Save(&Snapshot{
+		SyncedAt: now,
+		Users:    toBanSlice(newBans),
+	})
+	if err != nil {
+		m.fail(ctx, err)
+	}
+
+	m.emit(diff)
+
+	return diff, err
+}
+
+// Start starts syncing the mirror periodically in the background.
+// the first sync happens immediately.
+func (m *Mirror) Start() error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	if m.stopChan != nil {
+		return ErrAlreadyStarted
+	}
+
+	m.stopChan = make(chan struct{})
+	m.doneChan = make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go m.syncLoop(ctx, cancel, m.stopChan, m.doneChan)
+
+	return nil
+}
+
+// Stop stops the background syncing; the running sync (if any) gets
+// cancelled and Stop waits for it to return.
+func (m *Mirror) Stop() {
+	m.mut.Lock()
+	stopChan, doneChan := m.stopChan, m.doneChan
+	m.stopChan, m.doneChan = nil, nil
+	m.mut.Unlock()
+
+	if stopChan == nil {
+		return
+	}
+
+	close(stopChan)
+	<-doneChan
+}
+
+// IsBanned returns true if the user is banned according to the local copy
+// of the ban list. it never sends any request to the server.
+func (m *Mirror) IsBanned(userId int64) bool {
+	m.mut.RLock()
+	defer m.mut.RUnlock()
+
+	return m.bans[userId] != nil
+}
+
+// GetBan returns a copy of the ban information of the user from the local
+// copy of the ban list, or nil if the user isn't banned.
+func (m *Mirror) GetBan(userId int64) *sibylSystem.BanInfo {
+	m.mut.RLock()
+	defer m.mut.RUnlock()
+
+	info := m.bans[userId]
+	if info == nil {
+		return nil
+	}
+
+	tmp := *info
+	return &tmp
+}
+
+// ForEach calls fn for each ban in the local copy of the ban list, until
+// fn returns false.
+func (m *Mirror) ForEach(fn func(info *sibylSystem.BanInfo) bool) {
+	m.mut.RLock()
+	defer m.mut.RUnlock()
+
+	for _, current := range m.bans {
+		tmp := *current
+		if !fn(&tmp) {
+			return
+		}
+	}
+}
+
+// Length returns the count of banned users in the local copy.
+func (m *Mirror) Length() int {
+	m.mut.RLock()
+	defer m.mut.RUnlock()
+
+	return len(m.bans)
+}
+
+// GetSyncedAt returns the time of the last successful sync (or the time
+// of the snapshot loaded from the store).
+func (m *Mirror) GetSyncedAt() time.Time {
+	m.mut.RLock()
+	defer m.mut.RUnlock()
+
+	return m.syncedAt
+}
+
+// IsStale returns true if the local copy is older than the given duration,
+// or if it has never been synced at all.
+func (m *Mirror) IsStale(d time.Duration) bool {
+	syncedAt := m.GetSyncedAt()
+	return syncedAt.IsZero() || time.Since(syncedAt) > d
+}
+
+// GetLastError returns the error of the last sync, or nil if the
+// last sync was successful.
+func (m *Mirror) GetLastError() error {
+	m.mut.RLock()
+	defer m.mut.RUnlock()
+
+	return m.lastError
+}
+
+// SetOnChange sets the function which will be called for each change
+// of the ban list after a sync.
+func (m *Mirror) SetOnChange(fn func(*BanChange)) {
+	m.mut.Lock()
+	m.onChange = fn
+	m.mut.Unlock()
+}
+
+// SetOnSynced sets the function which will be called after each
+// successful sync, with all of the changes.
+func (m *Mirror) SetOnSynced(fn func(*MirrorDiff)) {
+	m.mut.Lock()
+	m.onSynced = fn
+	m.mut.Unlock()
+}
+
+// SetOnSyncFailed sets the function which will be called when
+// syncing the ban list fails; it's not called for the syncs cancelled
+// by their context (e.g. by Stop).
+func (m *Mirror) SetOnSyncFailed(fn func(error)) {
+	m.mut.Lock()
+	m.onSyncFailed = fn
+	m.mut.Unlock()
+}
+
+func (m *Mirror) syncLoop(
+	ctx context.Context,
+	cancel context.CancelFunc,
+	stopChan, doneChan chan struct{},
+) {
+	defer close(doneChan)
+
+	go func() {
+		<-stopChan
+		cancel()
+	}()
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		// errors are reported through onSyncFailed, the loop itself
+		// should keep going.
+		_, _ = m.SyncWithContext(ctx)
+
+		select {
+		case <-stopChan:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// fail records the error of a sync and reports it; cancelling the sync
+// isn't considered a failure.
+func (m *Mirror) fail(ctx context.Context, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	m.mut.Lock()
+	m.lastError = err
+	onSyncFailed := m.onSyncFailed
+	m.mut.Unlock()
+
+	if onSyncFailed != nil {
+		onSyncFailed(err)
+	}
+}
+
+func (m *Mirror) emit(diff *MirrorDiff) {
+	m.mut.RLock()
+	onChange, onSynced := m.onChange, m.onSynced
+	m.mut.RUnlock()
+
+	if onChange != nil {
+		diff.ForEach(func(change *BanChange) bool {
+			onChange(change)
+			return true
+		})
+	}
+
+	if onSynced != nil {
+		onSynced(diff)
+	}
+}
+
+//---------------------------------------------------------
+
+// IsEmpty returns true if nothing has changed between the two syncs.
+func (d *MirrorDiff) IsEmpty() bool {
+	return d.Length() == 0
+}
+
+// Length returns the total count of changes.
+func (d *MirrorDiff) Length() int {
+	return len(d.Added) + len(d.Removed) + len(d.Changed)
+}
+
+// ForEach calls fn for each change (added, then removed, then changed),
+// until fn returns false.
+func (d *MirrorDiff) ForEach(fn func(*BanChange) bool) {
+	for _, changes := range [][]*BanChange{d.Added, d.Removed, d.Changed} {
+		for _, current := range changes {
+			if !fn(current) {
+				return
+			}
+		}
+	}
+}
+
+//---------------------------------------------------------
+
+func (k ChangeKind) IsAdded() bool {
+	return k == ChangeKindAdded
+}
+
+func (k ChangeKind) IsRemoved() bool {
+	return k == ChangeKindRemoved
+}
+
+func (k ChangeKind) IsChanged() bool {
+	return k == ChangeKindChanged
+}
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeKindAdded:
+		return "added"
+	case ChangeKindRemoved:
+		return "removed"
+	case ChangeKindChanged:
+		return "changed"
+	default:
+		return "unknown"
+	}
+}
+
+//---------------------------------------------------------
+
+func (s *MemoryStore) Load() (*Snapshot, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	return s.snapshot, nil
+}
+
+func (s *MemoryStore) Save(snapshot *Snapshot) error {
+	s.mut.Lock()
+	s.snapshot = snapshot
+	s.mut.Unlock()
+
+	return nil
+}
+
+//---------------------------------------------------------
+
+func (s *FileStore) Load() (*Snapshot, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	snapshot := new(Snapshot)
+	found, err := sibylSystem.LoadJSONFile(s.path, snapshot)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// Save writes the snapshot to a temporary file first and then renames it,
+// so a crash in the middle of writing won't corrupt the previous snapshot.
+func (s *FileStore) Save(snapshot *Snapshot) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	return sibylSystem.SaveJSONFile(s.path, snapshot)
+}
+
+func (s *FileStore) GetPath() string {
+	return s.path
+}
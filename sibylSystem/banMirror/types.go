@@ -0,0 +1,92 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banMirror
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+)
+
+type ChangeKind int
+
+// Mirror keeps a local copy of the sibyl's ban list, syncs it periodically
+// and serves ban lookups from the local copy, so they keep working even
+// when the server is unreachable.
+type Mirror struct {
+	client   sibylSystem.SibylClient
+	store    Store
+	interval time.Duration
+
+	mut       *sync.RWMutex
+	bans      map[int64]*sibylSystem.BanInfo
+	syncedAt  time.Time
+	lastError error
+
+	syncMut  *sync.Mutex
+	stopChan chan struct{}
+	doneChan chan struct{}
+
+	onChange     func(*BanChange)
+	onSynced     func(*MirrorDiff)
+	onSyncFailed func(error)
+}
+
+type MirrorConfig struct {
+	// Store is the place where the mirrored ban list is persisted. if nil,
+	// the ban list will only be kept in the memory.
+	Store Store
+
+	// Interval is the time between two syncs. if zero, DefaultSyncInterval
+	// will be used.
+	Interval time.Duration
+}
+
+// Store is a persistent storage for the mirrored ban list.
+type Store interface {
+	// Load loads the last saved snapshot. it should return (nil, nil)
+	// if nothing has been saved yet.
+	Load() (*Snapshot, error)
+
+	// Save saves the given snapshot, replacing the previous one.
+	Save(snapshot *Snapshot) error
+}
+
+// Snapshot is the state of the ban list at a specific time.
+type Snapshot struct {
+	SyncedAt time.Time             `json:"synced_at"`
+	Users    []sibylSystem.BanInfo `json:"users"`
+}
+
+// MemoryStore is a Store which keeps the snapshot in the memory only.
+type MemoryStore struct {
+	mut      *sync.Mutex
+	snapshot *Snapshot
+}
+
+// FileStore is a Store which keeps the snapshot in a json file.
+type FileStore struct {
+	mut  *sync.Mutex
+	path string
+}
+
+// BanChange represents the change of a single user's ban between two syncs.
+// Previous is nil for added bans, and Current is nil for removed bans.
+type BanChange struct {
+	Kind     ChangeKind
+	UserId   int64
+	Previous *sibylSystem.BanInfo
+	Current  *sibylSystem.BanInfo
+}
+
+// MirrorDiff contains all of the changes between two syncs.
+type MirrorDiff struct {
+	Added    []*BanChange
+	Removed  []*BanChange
+	Changed  []*BanChange
+	SyncedAt time.Time
+}
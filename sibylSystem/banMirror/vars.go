@@ -0,0 +1,14 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banMirror
+
+import "errors"
+
+// error variables
+var (
+	ErrNoClient       = errors.New("a sibyl client is required for the mirror")
+	ErrAlreadyStarted = errors.New("mirror is already started")
+)
@@ -0,0 +1,16 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banPolicy
+
+const (
+	FormatUnknown Format = iota
+	FormatYAML
+	FormatJSON
+)
+
+// GlobalChatId is the chat id used for the default policy in the
+// reports of the simulator.
+const GlobalChatId int64 = 0
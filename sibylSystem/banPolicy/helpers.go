@@ -0,0 +1,216 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banPolicy
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/banQuery"
+	"gopkg.in/yaml.v3"
+)
+
+// NewEngine compiles the rules of the policies of the set and returns a
+// new engine; a nil set results in an engine which allows everyone.
+func NewEngine(set *PolicySet) (*Engine, error) {
+	e := &Engine{
+		mut:   &sync.RWMutex{},
+		chats: make(map[int64]*Policy),
+	}
+
+	if set == nil {
+		return e, nil
+	}
+
+	if set.Default != nil {
+		if err := compilePolicy(GlobalChatId, set.Default); err != nil {
+			return nil, err
+		}
+		e.defaultPolicy = set.Default
+	}
+
+	for chatId, policy := range set.Chats {
+		if policy == nil {
+			continue
+		}
+
+		if err := compilePolicy(chatId, policy); err != nil {
+			return nil, err
+		}
+		e.chats[chatId] = policy
+	}
+
+	return e, nil
+}
+
+// LoadEngine loads the policy file at the given path and returns a new
+// engine using it.
+func LoadEngine(path string) (*Engine, error) {
+	set, err := LoadPolicySet(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewEngine(set)
+}
+
+// DetectFormat detects the format of a policy file using its extension.
+func DetectFormat(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".json":
+		return FormatJSON
+	default:
+		return FormatUnknown
+	}
+}
+
+// LoadPolicySet reads the policy file at the given path, the format is
+// detected using the extension of the file.
+func LoadPolicySet(path string) (*PolicySet, error) {
+	format := DetectFormat(path)
+	if format == FormatUnknown {
+		return nil, ErrUnknownFormat
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParsePolicySet(data, format)
+}
+
+// ParsePolicySet parses a policy set in the given format.
+func ParsePolicySet(data []byte, format Format) (*PolicySet, error) {
+	set := new(PolicySet)
+	var err error
+	switch format {
+	case FormatYAML:
+		err = yaml.Unmarshal(data, set)
+	case FormatJSON:
+		err = json.Unmarshal(data, set)
+	default:
+		return nil, ErrUnknownFormat
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return set, nil
+}
+
+// CompileRule validates the rule and prepares it for matching; rules are
+// compiled by NewEngine and SetPolicy, so calling this is only needed to
+// use a rule on its own.
+func CompileRule(rule *Rule) error {
+	if rule.Action == nil {
+		return ErrNoAction
+	}
+
+	var filter *banQuery.Filter
+	var err error
+	if strings.TrimSpace(rule.Query) != "" {
+		filter, err = banQuery.Parse(rule.Query)
+		if err != nil {
+			return err
+		}
+	} else if rule.isEmpty() {
+		return ErrEmptyRule
+	} else {
+		filter = banQuery.NewFilter()
+	}
+
+	anyFlags, err := parseFlags(rule.Flags)
+	if err != nil {
+		return err
+	}
+
+	allFlags, err := parseFlags(rule.AllFlags)
+	if err != nil {
+		return err
+	}
+
+	if len(anyFlags) != 0 {
+		filter.AnyFlag(anyFlags...)
+	}
+
+	if len(allFlags) != 0 {
+		filter.AllFlags(allFlags...)
+	}
+
+	if rule.MinCoefficient != nil {
+		filter.MinCoefficient(*rule.MinCoefficient)
+	}
+
+	if rule.MaxCoefficient != nil {
+		filter.MaxCoefficient(*rule.MaxCoefficient)
+	}
+
+	var types []sibylSystem.EntityType
+	for _, current := range rule.TargetTypes {
+		entityType, err := sibylSystem.ParseEntityType(current)
+		if err != nil {
+			return err
+		}
+		types = append(types, entityType)
+	}
+
+	if len(types) != 0 {
+		filter.TargetType(types...)
+	}
+
+	for _, current := range rule.ReasonContains {
+		filter.ReasonContains(current)
+	}
+
+	if rule.ReasonMatches != "" {
+		pattern, err := banQuery.CompileReason(rule.ReasonMatches)
+		if err != nil {
+			return err
+		}
+		filter.ReasonMatches(pattern)
+	}
+
+	rule.filter = filter
+	return nil
+}
+
+func compilePolicy(chatId int64, policy *Policy) error {
+	for i, rule := range policy.Rules {
+		if rule == nil {
+			return &RuleError{ChatId: chatId, Index: i, Err: ErrEmptyRule}
+		}
+
+		if err := CompileRule(rule); err != nil {
+			return &RuleError{ChatId: chatId, Index: i, Name: rule.Name, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// parseFlags normalizes the flags of a rule; unlike the flags of bans,
+// unknown flags in the rules are always an error, since they would
+// silently never match.
+func parseFlags(flags []sibylSystem.BanFlag) ([]sibylSystem.BanFlag, error) {
+	var parsed []sibylSystem.BanFlag
+	for _, current := range flags {
+		flag, err := sibylSystem.ParseBanFlag(string(current))
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, flag)
+	}
+
+	return parsed, nil
+}
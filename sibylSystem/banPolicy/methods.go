@@ -0,0 +1,224 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banPolicy
+
+import (
+	"strconv"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/sibylGuard"
+)
+
+// Evaluate evaluates the info of a user against the policy of the chat.
+func (e *Engine) Evaluate(chatId int64, info *sibylSystem.GetInfoResult) *Evaluation {
+	if info == nil {
+		return &Evaluation{ChatId: chatId, Action: sibylGuard.ActionAllow}
+	}
+
+	return e.EvaluateBan(chatId, info.ToBanInfo())
+}
+
+// EvaluateBan evaluates a ban record against the policy of the chat.
+func (e *Engine) EvaluateBan(chatId int64, info *sibylSystem.BanInfo) *Evaluation {
+	evaluation := &Evaluation{
+		ChatId: chatId,
+		Action: sibylGuard.ActionAllow,
+	}
+
+	if info == nil {
+		return evaluation
+	}
+
+	e.mut.RLock()
+	policies := e.getPolicies(chatId)
+	e.mut.RUnlock()
+
+	if len(policies) == 0 {
+		return evaluation
+	}
+
+	evaluation.Action = policies[0].DefaultAction
+	for _, policy := range policies {
+		for _, rule := range policy.Rules {
+			if !rule.Match(info) {
+				continue
+			}
+
+			evaluation.Matches = append(evaluation.Matches, rule)
+			if evaluation.Rule == nil {
+				evaluation.Rule = rule
+				evaluation.Action = *rule.Action
+			}
+		}
+	}
+
+	return evaluation
+}
+
+// GetPolicy returns the policy of the chat; the default policy is
+// returned if the chat has no policy of its own.
+func (e *Engine) GetPolicy(chatId int64) *Policy {
+	e.mut.RLock()
+	defer e.mut.RUnlock()
+
+	if policy := e.chats[chatId]; policy != nil {
+		return policy
+	}
+
+	return e.defaultPolicy
+}
+
+// SetPolicy compiles and sets the policy of the chat; passing a nil
+// policy removes the policy of the chat. use GlobalChatId to set the
+// default policy.
+func (e *Engine) SetPolicy(chatId int64, policy *Policy) error {
+	if policy != nil {
+		if err := compilePolicy(chatId, policy); err != nil {
+			return err
+		}
+	}
+
+	e.mut.Lock()
+	defer e.mut.Unlock()
+
+	if chatId == GlobalChatId {
+		e.defaultPolicy = policy
+	} else if policy == nil {
+		delete(e.chats, chatId)
+	} else {
+		e.chats[chatId] = policy
+	}
+
+	return nil
+}
+
+// GuardPolicy returns a policy for sibylGuard which evaluates the users
+// against the policy of the chat of the event.
+func (e *Engine) GuardPolicy() sibylGuard.Policy {
+	return func(event *sibylGuard.Event, info *sibylSystem.GetInfoResult) sibylGuard.Action {
+		return e.Evaluate(event.ChatId, info).Action
+	}
+}
+
+// Simulate evaluates all of the bans from the given iteration function
+// against the policy of the chat, without taking any action; it can be
+// used with the ForEach method of a mirror, e.g.
+// engine.Simulate(chatId, mirror.ForEach).
+func (e *Engine) Simulate(chatId int64, forEach func(fn func(info *sibylSystem.BanInfo) bool)) *SimulationReport {
+	report := &SimulationReport{
+		ChatId:     chatId,
+		Actions:    make(map[sibylGuard.Action]int),
+		Rules:      make(map[string]int),
+		Samples:    make(map[sibylGuard.Action][]int64),
+		SampleSize: DefaultSampleSize,
+	}
+
+	forEach(func(info *sibylSystem.BanInfo) bool {
+		report.add(info, e.EvaluateBan(chatId, info))
+		return true
+	})
+
+	return report
+}
+
+func (e *Engine) getPolicies(chatId int64) []*Policy {
+	policy := e.chats[chatId]
+	if policy == nil {
+		if e.defaultPolicy == nil {
+			return nil
+		}
+		return []*Policy{e.defaultPolicy}
+	}
+
+	if policy.Inherit && e.defaultPolicy != nil {
+		return []*Policy{policy, e.defaultPolicy}
+	}
+
+	return []*Policy{policy}
+}
+
+//---------------------------------------------------------
+
+// Match returns true if the ban matches all of the conditions of the rule.
+func (r *Rule) Match(info *sibylSystem.BanInfo) bool {
+	if r.filter == nil || info == nil {
+		return false
+	}
+
+	if !info.Banned && !r.IncludeUnbanned {
+		return false
+	}
+
+	return r.filter.Match(info)
+}
+
+// isEmpty returns true if the rule has no conditions; IncludeUnbanned
+// only changes which users the conditions apply to, so it's not one.
+func (r *Rule) isEmpty() bool {
+	return len(r.Flags) == 0 && len(r.AllFlags) == 0 &&
+		r.MinCoefficient == nil && r.MaxCoefficient == nil &&
+		len(r.TargetTypes) == 0 && len(r.ReasonContains) == 0 &&
+		r.ReasonMatches == ""
+}
+
+//---------------------------------------------------------
+
+// IsAllowed returns true if the user is allowed by the policy.
+func (e *Evaluation) IsAllowed() bool {
+	return e.Action == sibylGuard.ActionAllow
+}
+
+// GetRuleName returns the name of the rule which decided the action, or
+// an empty string if no rule matched.
+func (e *Evaluation) GetRuleName() string {
+	if e.Rule == nil {
+		return ""
+	}
+
+	return e.Rule.Name
+}
+
+//---------------------------------------------------------
+
+// GetCount returns the count of the users for which the action has been
+// decided.
+func (r *SimulationReport) GetCount(action sibylGuard.Action) int {
+	return r.Actions[action]
+}
+
+// GetAffected returns the count of the users who would not be allowed.
+func (r *SimulationReport) GetAffected() int {
+	return r.Total - r.Actions[sibylGuard.ActionAllow]
+}
+
+func (r *SimulationReport) add(info *sibylSystem.BanInfo, evaluation *Evaluation) {
+	r.Total++
+	r.Actions[evaluation.Action]++
+	if evaluation.Rule != nil {
+		r.Rules[evaluation.Rule.Name]++
+	}
+
+	if evaluation.Action != sibylGuard.ActionAllow &&
+		len(r.Samples[evaluation.Action]) < r.SampleSize {
+		r.Samples[evaluation.Action] = append(r.Samples[evaluation.Action], info.UserId)
+	}
+}
+
+//---------------------------------------------------------
+
+func (e *RuleError) Error() string {
+	name := e.Name
+	if name == "" {
+		name = "#" + strconv.Itoa(e.Index)
+	}
+
+	return "invalid rule " + name + " in the policy of chat " +
+		strconv.FormatInt(e.ChatId, 10) + ": " + e.Err.Error()
+}
+
+func (e *RuleError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,123 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banPolicy
+
+import (
+	"sync"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/banQuery"
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/sibylGuard"
+)
+
+type Format int
+
+// PolicySet is the content of a policy file: a default policy, and the
+// policies of specific chats.
+type PolicySet struct {
+	Default *Policy           `json:"default" yaml:"default"`
+	Chats   map[int64]*Policy `json:"chats" yaml:"chats"`
+}
+
+// Policy is the list of rules of a chat. the rules are checked in order,
+// and the action of the first matching rule is taken.
+type Policy struct {
+	Rules []*Rule `json:"rules" yaml:"rules"`
+
+	// DefaultAction is the action taken when no rule matches.
+	DefaultAction sibylGuard.Action `json:"default_action" yaml:"default_action"`
+
+	// Inherit makes the rules of the default policy to be checked after
+	// the rules of this policy.
+	Inherit bool `json:"inherit" yaml:"inherit"`
+}
+
+// Rule matches the info of users; all of the conditions which are set
+// must match. rules only match banned users, unless IncludeUnbanned is set.
+type Rule struct {
+	Name string `json:"name" yaml:"name"`
+
+	// Flags matches the users having at least one of the flags.
+	Flags []sibylSystem.BanFlag `json:"flags" yaml:"flags"`
+
+	// AllFlags matches the users having all of the flags.
+	AllFlags []sibylSystem.BanFlag `json:"all_flags" yaml:"all_flags"`
+
+	MinCoefficient *int64 `json:"min_coefficient" yaml:"min_coefficient"`
+	MaxCoefficient *int64 `json:"max_coefficient" yaml:"max_coefficient"`
+
+	// TargetTypes are the names of the entity types, e.g. "user" or "bot".
+	TargetTypes []string `json:"target_types" yaml:"target_types"`
+
+	// ReasonContains matches the reasons containing all of the texts,
+	// case insensitive.
+	ReasonContains []string `json:"reason_contains" yaml:"reason_contains"`
+
+	// ReasonMatches is a regular expression matched against the reason.
+	ReasonMatches string `json:"reason_matches" yaml:"reason_matches"`
+
+	// Query is a query in the syntax of banQuery, for the conditions which
+	// have no field of their own, e.g. "since:30d by:12345".
+	Query string `json:"query" yaml:"query"`
+
+	IncludeUnbanned bool `json:"include_unbanned" yaml:"include_unbanned"`
+
+	// Action is the action taken if the rule matches; it's required, since
+	// a missing action would silently allow the matching users.
+	Action *sibylGuard.Action `json:"action" yaml:"action"`
+
+	filter *banQuery.Filter
+}
+
+// Engine evaluates the info of users against the policies of chats.
+type Engine struct {
+	mut           *sync.RWMutex
+	defaultPolicy *Policy
+	chats         map[int64]*Policy
+}
+
+// Evaluation is the result of evaluating the info of a user.
+type Evaluation struct {
+	ChatId int64
+	Action sibylGuard.Action
+
+	// Rule is the rule which decided the action; it's nil if no rule
+	// matched and the default action has been taken.
+	Rule *Rule
+
+	// Matches are all of the rules which matched, in order.
+	Matches []*Rule
+}
+
+// SimulationReport shows the impact of a policy on a ban list.
+type SimulationReport struct {
+	ChatId int64
+
+	// Total is the count of the records checked.
+	Total int
+
+	// Actions is the count of users for each action.
+	Actions map[sibylGuard.Action]int
+
+	// Rules is the count of users for which each rule decided the action,
+	// by the name of the rules.
+	Rules map[string]int
+
+	// Samples contains up to SampleSize user ids for each action other
+	// than allow.
+	Samples map[sibylGuard.Action][]int64
+
+	// SampleSize is the max count of the samples of each action.
+	SampleSize int
+}
+
+// RuleError is returned when a rule of a policy is invalid.
+type RuleError struct {
+	ChatId int64
+	Index  int
+	Name   string
+	Err    error
+}
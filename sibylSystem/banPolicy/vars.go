@@ -0,0 +1,18 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banPolicy
+
+import "errors"
+
+var (
+	ErrUnknownFormat = errors.New("unknown policy file format")
+	ErrEmptyRule     = errors.New("rule has no conditions")
+	ErrNoAction      = errors.New("rule has no action")
+)
+
+// DefaultSampleSize is the default max count of the samples of each
+// action in the reports of the simulator.
+var DefaultSampleSize = 10
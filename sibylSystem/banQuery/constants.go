@@ -0,0 +1,19 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banQuery
+
+// query keys.
+const (
+	keyFlag    = "flag"
+	keyAnyFlag = "anyflag"
+	keyAllFlag = "allflag"
+	keyCoef    = "coef"
+	keyBy      = "by"
+	keyType    = "type"
+	keySince   = "since"
+	keyUntil   = "until"
+	keyReason  = "reason"
+)
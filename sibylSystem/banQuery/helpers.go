@@ -0,0 +1,256 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banQuery
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+)
+
+// NewFilter returns a new empty filter, which matches all of the bans.
+func NewFilter() *Filter {
+	return &Filter{}
+}
+
+// Parse parses the given text query to a filter. a query is made of terms
+// separated by spaces, and a ban should match all of them:
+//
+//	flag:SPAM,RAID       has at least one of the flags (same as anyflag:)
+//	allflag:SPAM,RAID    has all of the flags
+//	coef>300             crime coefficient over 300 (>=, <, <= also work)
+//	coef:100..300        crime coefficient between 100 and 300
+//	by:123,456           banned by one of the given ids
+//	type:bot,user        target type is one of the given types
+//	since:7d             banned in the last 7 days (m, h, d, w units)
+//	until:2022-05-01     banned before the given date
+//	reason:"mass add"    reason contains the text (case insensitive)
+//	reason~^spam         reason matches the regular expression
+//	"some words"         same as reason:"some words"
+func Parse(query string) (*Filter, error) {
+	return ParseAt(query, time.Now())
+}
+
+// ParseAt is the same as Parse, but relative dates are computed
+// from the given time instead of the current time.
+func ParseAt(query string, now time.Time) (*Filter, error) {
+	terms, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+
+	f := NewFilter()
+	for _, current := range terms {
+		err = f.applyTerm(current, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
+
+// ParseDate parses a date of the query, trying all of the layouts known
+// by sibylSystem.ParseSibylTime.
+func ParseDate(value string) (time.Time, bool) {
+	t, err := sibylSystem.ParseSibylTime(value)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return t.Time, true
+}
+
+// tokenize splits the query by spaces, keeping the quoted parts together.
+func tokenize(query string) ([]queryTerm, error) {
+	var terms []queryTerm
+	var current strings.Builder
+	inQuote, quoted, hasTerm := false, false, false
+
+	flush := func() {
+		if hasTerm {
+			terms = append(terms, queryTerm{text: current.String(), quoted: quoted})
+		}
+		current.Reset()
+		quoted, hasTerm = false, false
+	}
+
+	for _, r := range query {
+		switch {
+		case r == '"':
+			if !hasTerm {
+				quoted = true
+			}
+			inQuote = !inQuote
+			hasTerm = true
+		case !inQuote && (r == ' ' || r == '\t' || r == '\n'):
+			flush()
+		default:
+			current.WriteRune(r)
+			hasTerm = true
+		}
+	}
+
+	if inQuote {
+		return nil, &QueryError{Term: current.String(), Err: ErrUnterminatedQuote}
+	}
+	flush()
+
+	return terms, nil
+}
+
+// splitTerm splits the term to its key, operator and value; ok is false
+// if the term doesn't have any operator.
+func splitTerm(text string) (key, op, value string, ok bool) {
+	index := strings.IndexAny(text, ":<>~=")
+	if index <= 0 {
+		return "", "", "", false
+	}
+
+	key = strings.ToLower(text[:index])
+	op = text[index : index+1]
+	value = text[index+1:]
+	if (op == ">" || op == "<") && strings.HasPrefix(value, "=") {
+		op += "="
+		value = value[1:]
+	}
+
+	return key, op, value, true
+}
+
+func splitList(value string) []string {
+	var values []string
+	for _, current := range strings.Split(value, ",") {
+		current = strings.TrimSpace(current)
+		if current != "" {
+			values = append(values, current)
+		}
+	}
+
+	return values
+}
+
+// parseFlags parses the flags of a query; unknown flags are kept as they
+// are, since the server may send flags which aren't registered here.
+func parseFlags(value string) []sibylSystem.BanFlag {
+	var flags []sibylSystem.BanFlag
+	for _, current := range splitList(value) {
+		flag, _ := sibylSystem.ParseBanFlag(current)
+		flags = append(flags, flag)
+	}
+
+	return flags
+}
+
+func parseIds(value string) ([]int64, error) {
+	var ids []int64
+	for _, current := range splitList(value) {
+		id, err := strconv.ParseInt(current, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+func parseEntityTypes(value string) ([]sibylSystem.EntityType, error) {
+	var types []sibylSystem.EntityType
+	for _, current := range splitList(value) {
+		entityType, err := sibylSystem.ParseEntityType(current)
+		if err != nil {
+			return nil, ErrInvalidValue
+		}
+		types = append(types, entityType)
+	}
+
+	return types, nil
+}
+
+// parseQueryDate parses either an absolute date, or a relative one
+// (e.g. "7d", which means 7 days before now).
+func parseQueryDate(value string, now time.Time) (time.Time, bool) {
+	if t, ok := ParseDate(value); ok {
+		return t, true
+	}
+
+	if len(value) < 2 {
+		return time.Time{}, false
+	}
+
+	unit, ok := relativeUnits[value[len(value)-1]]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || n < 0 {
+		return time.Time{}, false
+	}
+
+	return now.Add(-time.Duration(n) * unit), true
+}
+
+// parseCoefficientRange parses "100..300", "100.." or "..300"; a single
+// number means exactly that coefficient.
+func parseCoefficientRange(value string) (min, max *int64, err error) {
+	parts := strings.SplitN(value, "..", 2)
+	if len(parts) == 1 {
+		n, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &n, &n, nil
+	}
+
+	if parts[0] != "" {
+		n, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			return nil, nil, err
+		}
+		min = &n
+	}
+
+	if parts[1] != "" {
+		n, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return nil, nil, err
+		}
+		max = &n
+	}
+
+	return min, max, nil
+}
+
+// CompileReason compiles a pattern for Filter.ReasonMatches the same way
+// as the "reason~" term of the queries, which is case-insensitive.
+func CompileReason(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("(?i)" + pattern)
+}
+
+func containsInt64(values []int64, value int64) bool {
+	for _, current := range values {
+		if current == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsFlag(values []sibylSystem.BanFlag, value sibylSystem.BanFlag) bool {
+	for _, current := range values {
+		if strings.EqualFold(string(current), string(value)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,304 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banQuery
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+)
+
+// AnyFlag makes the filter match bans having at least one of the flags.
+func (f *Filter) AnyFlag(flags ...sibylSystem.BanFlag) *Filter {
+	f.anyFlags = append(f.anyFlags, flags...)
+	return f
+}
+
+// AllFlags makes the filter match bans having all of the flags.
+func (f *Filter) AllFlags(flags ...sibylSystem.BanFlag) *Filter {
+	f.allFlags = append(f.allFlags, flags...)
+	return f
+}
+
+// MinCoefficient makes the filter match bans with a crime coefficient
+// of at least the given value.
+func (f *Filter) MinCoefficient(value int64) *Filter {
+	f.minCoefficient = &value
+	return f
+}
+
+// MaxCoefficient makes the filter match bans with a crime coefficient
+// of at most the given value.
+func (f *Filter) MaxCoefficient(value int64) *Filter {
+	f.maxCoefficient = &value
+	return f
+}
+
+// CoefficientRange makes the filter match bans with a crime coefficient
+// between min and max (inclusive).
+func (f *Filter) CoefficientRange(min, max int64) *Filter {
+	return f.MinCoefficient(min).MaxCoefficient(max)
+}
+
+// Since makes the filter match bans made at or after the given time.
+func (f *Filter) Since(t time.Time) *Filter {
+	f.since = t
+	return f
+}
+
+// Until makes the filter match bans made at or before the given time.
+func (f *Filter) Until(t time.Time) *Filter {
+	f.until = t
+	return f
+}
+
+// DateRange makes the filter match bans made between the given times.
+func (f *Filter) DateRange(since, until time.Time) *Filter {
+	return f.Since(since).Until(until)
+}
+
+// BannedBy makes the filter match bans made by one of the given ids.
+func (f *Filter) BannedBy(ids ...int64) *Filter {
+	f.bannedBy = append(f.bannedBy, ids...)
+	return f
+}
+
+// TargetType makes the filter match bans of one of the given types.
+func (f *Filter) TargetType(types ...sibylSystem.EntityType) *Filter {
+	f.targetTypes = append(f.targetTypes, types...)
+	return f
+}
+
+// ReasonContains makes the filter match bans with a reason containing
+// the given text (case insensitive).
+func (f *Filter) ReasonContains(text string) *Filter {
+	f.reasonContains = append(f.reasonContains, strings.ToLower(text))
+	return f
+}
+
+// ReasonMatches makes the filter match bans with a reason matching the
+// given regular expression.
+func (f *Filter) ReasonMatches(pattern *regexp.Regexp) *Filter {
+	f.reasonPatterns = append(f.reasonPatterns, pattern)
+	return f
+}
+
+// Match returns true if the ban matches all of the conditions of the filter.
+func (f *Filter) Match(info *sibylSystem.BanInfo) bool {
+	if info == nil {
+		return false
+	}
+
+	if len(f.anyFlags) != 0 && !f.matchAnyFlag(info.BanFlags) {
+		return false
+	}
+
+	for _, current := range f.allFlags {
+		if !containsFlag(info.BanFlags, current) {
+			return false
+		}
+	}
+
+	if f.minCoefficient != nil && int64(info.CrimeCoefficient) < *f.minCoefficient {
+		return false
+	}
+
+	if f.maxCoefficient != nil && int64(info.CrimeCoefficient) > *f.maxCoefficient {
+		return false
+	}
+
+	if !f.since.IsZero() || !f.until.IsZero() {
+		date := info.Date.Time
+		if date.IsZero() || (!f.since.IsZero() && date.Before(f.since)) ||
+			(!f.until.IsZero() && date.After(f.until)) {
+			return false
+		}
+	}
+
+	if len(f.bannedBy) != 0 && !containsInt64(f.bannedBy, info.BannedBy) {
+		return false
+	}
+
+	if len(f.targetTypes) != 0 && !f.matchTargetType(info.TargetType) {
+		return false
+	}
+
+	reason := strings.ToLower(info.Reason)
+	for _, current := range f.reasonContains {
+		if !strings.Contains(reason, current) {
+			return false
+		}
+	}
+
+	for _, current := range f.reasonPatterns {
+		if !current.MatchString(info.Reason) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Apply returns the bans of the result which match the filter.
+func (f *Filter) Apply(result *sibylSystem.GetBansResult) []sibylSystem.BanInfo {
+	var matched []sibylSystem.BanInfo
+	if result == nil {
+		return matched
+	}
+
+	for i := range result.Users {
+		if f.Match(&result.Users[i]) {
+			matched = append(matched, result.Users[i])
+		}
+	}
+
+	return matched
+}
+
+// Collect returns the bans which match the filter from the given iteration
+// function; it can be used with the ForEach method of a mirror, or any
+// other source of bans, e.g. filter.Collect(mirror.ForEach).
+func (f *Filter) Collect(forEach func(fn func(info *sibylSystem.BanInfo) bool)) []sibylSystem.BanInfo {
+	var matched []sibylSystem.BanInfo
+	forEach(func(info *sibylSystem.BanInfo) bool {
+		if f.Match(info) {
+			matched = append(matched, *info)
+		}
+		return true
+	})
+
+	return matched
+}
+
+// Count returns the count of the bans which match the filter from the
+// given iteration function.
+func (f *Filter) Count(forEach func(fn func(info *sibylSystem.BanInfo) bool)) int {
+	count := 0
+	forEach(func(info *sibylSystem.BanInfo) bool {
+		if f.Match(info) {
+			count++
+		}
+		return true
+	})
+
+	return count
+}
+
+func (f *Filter) matchAnyFlag(flags []sibylSystem.BanFlag) bool {
+	for _, current := range f.anyFlags {
+		if containsFlag(flags, current) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *Filter) matchTargetType(targetType sibylSystem.EntityType) bool {
+	for _, current := range f.targetTypes {
+		if current == targetType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *Filter) applyTerm(term queryTerm, now time.Time) error {
+	key, op, value, ok := splitTerm(term.text)
+	if term.quoted || !ok {
+		f.ReasonContains(term.text)
+		return nil
+	}
+
+	var err error
+	switch {
+	case (key == keyFlag || key == keyAnyFlag) && op == ":":
+		f.AnyFlag(parseFlags(value)...)
+	case key == keyAllFlag && op == ":":
+		f.AllFlags(parseFlags(value)...)
+	case key == keyCoef:
+		err = f.applyCoefficient(op, value)
+	case key == keyBy && op == ":":
+		var ids []int64
+		ids, err = parseIds(value)
+		f.BannedBy(ids...)
+	case key == keyType && op == ":":
+		var types []sibylSystem.EntityType
+		types, err = parseEntityTypes(value)
+		f.TargetType(types...)
+	case (key == keySince || key == keyUntil) && op == ":":
+		date, ok := parseQueryDate(value, now)
+		if !ok {
+			err = ErrInvalidValue
+		} else if key == keySince {
+			f.Since(date)
+		} else {
+			f.Until(date)
+		}
+	case key == keyReason && op == ":":
+		f.ReasonContains(value)
+	case key == keyReason && op == "~":
+		var pattern *regexp.Regexp
+		pattern, err = CompileReason(value)
+		f.ReasonMatches(pattern)
+	default:
+		return &QueryError{Term: term.text, Err: ErrUnknownKey}
+	}
+
+	if err != nil {
+		return &QueryError{Term: term.text, Err: err}
+	}
+
+	return nil
+}
+
+func (f *Filter) applyCoefficient(op, value string) error {
+	if op == ":" {
+		min, max, err := parseCoefficientRange(value)
+		if err != nil {
+			return err
+		}
+
+		f.minCoefficient, f.maxCoefficient = min, max
+		return nil
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	switch op {
+	case ">":
+		f.MinCoefficient(n + 1)
+	case ">=":
+		f.MinCoefficient(n)
+	case "<":
+		f.MaxCoefficient(n - 1)
+	case "<=":
+		f.MaxCoefficient(n)
+	case "=":
+		f.CoefficientRange(n, n)
+	default:
+		return ErrInvalidValue
+	}
+
+	return nil
+}
+
+//---------------------------------------------------------
+
+func (e *QueryError) Error() string {
+	return "invalid query term " + strconv.Quote(e.Term) + ": " + e.Err.Error()
+}
+
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,42 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banQuery
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+)
+
+// Filter matches ban records against a set of conditions; a record matches
+// the filter only if it matches all of the conditions. the zero value
+// matches all of the bans.
+type Filter struct {
+	anyFlags       []sibylSystem.BanFlag
+	allFlags       []sibylSystem.BanFlag
+	minCoefficient *int64
+	maxCoefficient *int64
+	since          time.Time
+	until          time.Time
+	bannedBy       []int64
+	targetTypes    []sibylSystem.EntityType
+	reasonContains []string
+	reasonPatterns []*regexp.Regexp
+}
+
+// QueryError is the error of parsing a single term of a text query.
+type QueryError struct {
+	Term string
+	Err  error
+}
+
+// queryTerm is a single term of a query; quoted terms are always
+// treated as plain text.
+type queryTerm struct {
+	text   string
+	quoted bool
+}
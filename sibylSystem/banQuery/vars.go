@@ -0,0 +1,26 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package banQuery
+
+import (
+	"errors"
+	"time"
+)
+
+// error variables
+var (
+	ErrUnknownKey        = errors.New("unknown query key")
+	ErrInvalidValue      = errors.New("invalid value for the query key")
+	ErrUnterminatedQuote = errors.New("unterminated quote in the query")
+)
+
+// relativeUnits are the units accepted in relative dates, e.g. "7d".
+var relativeUnits = map[byte]time.Duration{
+	'm': time.Minute,
+	'h': time.Hour,
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+}
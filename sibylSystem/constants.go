@@ -5,6 +5,8 @@
 
 package sibylSystem
 
+import "time"
+
 const (
 	DefaultUrl = "https://psychopass.kaizoku.cyou/"
 )
@@ -22,11 +24,40 @@ const (
 
 const (
 	DefaultDispatcherTimeout = 30
+
+	// DefaultDispatcherWorkers is the default count of the goroutines
+	// running the handlers of the dispatcher.
+	DefaultDispatcherWorkers = 8
+
+	// DefaultDispatcherQueueSize is the default count of the updates which
+	// can wait for a worker; when the queue is full, the dispatcher stops
+	// getting new updates until a worker is free.
+	DefaultDispatcherQueueSize = 64
+)
+
+const (
+	// DefaultBulkConcurrency is the default count of requests which are sent
+	// at the same time by bulk operations.
+	DefaultBulkConcurrency = 8
+)
+
+// bulk operation names, used for making sure a checkpoint file belongs
+// to the same kind of operation.
+const (
+	bulkOperationBan    = "ban"
+	bulkOperationRevert = "revert"
+)
+
+const (
+	// DefaultTimeLayout is the layout used for displaying times.
+	DefaultTimeLayout = "2006-01-02 15:04:05 MST"
+	// ShortDateLayout is the layout used for displaying dates in short form.
+	ShortDateLayout = "2006-01-02"
 )
 
 const (
-	UpdateTypeScanRequestApproved = "scan_request_approved"
-	UpdateTypeScanRequestRejected = "scan_request_rejected"
+	UpdateTypeScanRequestApproved SibylUpdateType = "scan_request_approved"
+	UpdateTypeScanRequestRejected SibylUpdateType = "scan_request_rejected"
 )
 
 const (
@@ -55,14 +86,163 @@ const (
 
 // flags constants
 const (
-	BanFlagTrolling     = "TROLLING"
-	BanFlagSpam         = "SPAM"
-	BanFlagEvade        = "EVADE"
-	BanFlagCustom       = "CUSTOM"
-	BanFlagPsychoHazard = "PSYCHOHAZARD"
-	BanFlagMalImp       = "MALIMP"
-	BanFlagNSFW         = "NSFW"
-	BanFlagRaid         = "RAID"
-	BanFlagSpamBot      = "SPAMBOT"
-	BanFlagMassAdd      = "MASSADD"
+	BanFlagTrolling     BanFlag = "TROLLING"
+	BanFlagSpam         BanFlag = "SPAM"
+	BanFlagEvade        BanFlag = "EVADE"
+	BanFlagCustom       BanFlag = "CUSTOM"
+	BanFlagPsychoHazard BanFlag = "PSYCHOHAZARD"
+	BanFlagMalImp       BanFlag = "MALIMP"
+	BanFlagNSFW         BanFlag = "NSFW"
+	BanFlagRaid         BanFlag = "RAID"
+	BanFlagSpamBot      BanFlag = "SPAMBOT"
+	BanFlagMassAdd      BanFlag = "MASSADD"
+)
+
+const (
+	// CrimeBandNormal is for the coefficients under 100; the user is not
+	// a target for enforcement action.
+	CrimeBandNormal CrimeBand = iota
+	// CrimeBandLatentCriminal is for the coefficients from 100 to 199.
+	CrimeBandLatentCriminal
+	// CrimeBandParalyzer is for the coefficients from 200 to 299.
+	CrimeBandParalyzer
+	// CrimeBandLethalEliminator is for the coefficients of 300 and above.
+	CrimeBandLethalEliminator
+)
+
+// the lower bounds of the crime bands.
+const (
+	LatentCriminalThreshold   CrimeCoefficient = 100
+	ParalyzerThreshold        CrimeCoefficient = 200
+	LethalEliminatorThreshold CrimeCoefficient = 300
+)
+
+const (
+	// ActionNone means no action is needed against the user.
+	ActionNone EnforcementAction = iota
+	// ActionWarn means the user should be warned and watched.
+	ActionWarn
+	// ActionMute means the user should be restricted from sending messages.
+	ActionMute
+	// ActionBan means the user should be banned from the chat.
+	ActionBan
+)
+
+const (
+	StyleBold TextStyle = iota
+	StyleItalic
+	StyleUnderline
+	StyleStrike
+	StyleSpoiler
+	StyleMono
+)
+
+// parse modes of telegram.
+const (
+	ParseModeMarkdownV2 = "MarkdownV2"
+	ParseModeHTML       = "HTML"
+)
+
+// keys of the message catalog; see RegisterLocale.
+const (
+	MsgKeyYes                  = "yes"
+	MsgKeyNo                   = "no"
+	MsgKeyListSeparator        = "list.separator"
+	MsgKeyListLastSeparator    = "list.last-separator"
+	MsgKeyEstimateUnder        = "coefficient.under"
+	MsgKeyEstimateOver         = "coefficient.over"
+	MsgKeyEstimateExactly      = "coefficient.exactly"
+	MsgKeyJustNow              = "time.just-now"
+	MsgKeyTimeAgo              = "time.ago"
+	MsgKeyTimeIn               = "time.in"
+	MsgKeyUnitSecond           = "time.unit.second"
+	MsgKeyUnitMinute           = "time.unit.minute"
+	MsgKeyUnitHour             = "time.unit.hour"
+	MsgKeyUnitDay              = "time.unit.day"
+	MsgKeyUnitWeek             = "time.unit.week"
+	MsgKeyUnitMonth            = "time.unit.month"
+	MsgKeyUnitYear             = "time.unit.year"
+	MsgKeyBandNormal           = "band.normal"
+	MsgKeyBandLatentCriminal   = "band.latent-criminal"
+	MsgKeyBandParalyzer        = "band.paralyzer"
+	MsgKeyBandLethalEliminator = "band.lethal-eliminator"
+	MsgKeyBandUnknown          = "band.unknown"
+	MsgKeyPermNormalUser       = "permission.normal-user"
+	MsgKeyPermEnforcer         = "permission.enforcer"
+	MsgKeyPermInspector        = "permission.inspector"
+	MsgKeyPermOwner            = "permission.owner"
+	MsgKeyEntityUser           = "entity.user"
+	MsgKeyEntityBot            = "entity.bot"
+	MsgKeyEntityAdmin          = "entity.admin"
+	MsgKeyEntityOwner          = "entity.owner"
+	MsgKeyEntityChannel        = "entity.channel"
+	MsgKeyEntityGroup          = "entity.group"
+	MsgKeyUnknown              = "unknown"
+
+	// MsgKeyFlagPrefix is the prefix of the keys of flag names, followed
+	// by the flag in lowercase, e.g. "flag.spambot". flag descriptions use
+	// the same key with MsgKeyDescriptionSuffix appended to it.
+	MsgKeyFlagPrefix        = "flag."
+	MsgKeyDescriptionSuffix = ".description"
+)
+
+// PermaMarker is the word added to the reason of permanent bans; sibyl
+// has no separate field for it, so it's detected from the reason text.
+const PermaMarker = "perma"
+
+const (
+	// SeverityLow is for the flags which are mostly annoying, but not harmful.
+	SeverityLow BanSeverity = iota + 1
+	// SeverityMedium is for the flags which are harmful to the users.
+	SeverityMedium
+	// SeverityHigh is for the flags which are harmful to the groups.
+	SeverityHigh
+	// SeverityCritical is for the flags which can destroy a whole group.
+	SeverityCritical
+)
+
+const (
+	// DegradeFailOpen considers the users not banned when they can't be
+	// checked.
+	DegradeFailOpen DegradationPolicy = iota
+	// DegradeFailClosed considers the users banned when they can't be
+	// checked.
+	DegradeFailClosed
+	// DegradeUseStale uses the last known data of the users (either a
+	// previous lookup or the mirror), as long as it isn't older than
+	// BanCheckerConfig.MaxStaleness.
+	DegradeUseStale
+)
+
+const (
+	// VerdictSourceDefault means the verdict is decided by the degradation
+	// policy, without any data of the user.
+	VerdictSourceDefault VerdictSource = iota
+	// VerdictSourceLive means the verdict is based on a live lookup.
+	VerdictSourceLive
+	// VerdictSourceCache means the verdict is based on a cached lookup,
+	// which might be stale if the live lookup has failed.
+	VerdictSourceCache
+	// VerdictSourceMirror means the verdict is based on the local copy of
+	// the ban list.
+	VerdictSourceMirror
+)
+
+// DefaultMaxStaleness is the default max age of the data used when the
+// live lookups fail.
+const DefaultMaxStaleness = 6 * time.Hour
+
+const (
+	// DefaultBackoffInitialDelay is the default delay before the first retry
+	// of the dispatcher.
+	DefaultBackoffInitialDelay = time.Second
+	// DefaultBackoffMaxDelay is the default max delay between two retries
+	// of the dispatcher.
+	DefaultBackoffMaxDelay = time.Minute
+	// DefaultBackoffMultiplier is the default factor the delay is multiplied
+	// by after each failed attempt.
+	DefaultBackoffMultiplier = 2.0
+	// DefaultBackoffJitter is the default ratio of the random change of the
+	// delays, so many clients won't retry at the same time.
+	DefaultBackoffJitter = 0.2
 )
@@ -7,10 +7,24 @@ package sibylSystem
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
 	"net/http"
+	urlLib "net/url"
+	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode"
 
 	"github.com/AnimeKaizoku/ssg/ssg"
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message/catalog"
 )
 
 func NewClient(token string, config *SibylConfig) SibylClient {
@@ -19,9 +33,16 @@ func NewClient(token string, config *SibylConfig) SibylClient {
 	}
 
 	core := &sibylCore{
-		Token:      token,
-		HostUrl:    validateHostUrl(config.HostUrl),
-		HttpClient: config.HttpClient,
+		Token:             token,
+		HostUrl:           validateHostUrl(config.HostUrl),
+		HttpClient:        config.HttpClient,
+		Context:           config.Context,
+		RateLimiter:       config.RateLimiter,
+		infoCache:         newInfoCache(config.InfoCacheTTL),
+		infoFlight:        newFlightGroup[flightKey, *GetInfoResult](),
+		generalInfoFlight: newFlightGroup[flightKey, *GeneralInfoResult](),
+		statsFlight:       newFlightGroup[flightKey, *GetStatsResult](),
+		tokenFlight:       newFlightGroup[flightKey, *TokenInfo](),
 	}
 
 	if core.Context == nil {
@@ -35,15 +56,105 @@ func NewClient(token string, config *SibylConfig) SibylClient {
 	return core
 }
 
+// NewBanChecker returns a new ban checker using the client. enabling the
+// info cache of the client (SibylConfig.InfoCacheTTL) is recommended.
+func NewBanChecker(client SibylClient, config *BanCheckerConfig) (*BanChecker, error) {
+	if client == nil {
+		return nil, ErrNoClient
+	}
+
+	if config == nil {
+		config = GetDefaultBanCheckerConfig()
+	}
+
+	c := &BanChecker{
+		client:        client,
+		mirror:        config.Mirror,
+		policy:        config.Policy,
+		staleFallback: config.StaleFallback,
+		maxStaleness:  config.MaxStaleness,
+	}
+
+	if c.maxStaleness <= 0 {
+		c.maxStaleness = DefaultMaxStaleness
+	}
+
+	if c.staleFallback == DegradeUseStale {
+		c.staleFallback = DegradeFailOpen
+	}
+
+	if c.policy == DegradeUseStale {
+		c.lastKnown = newInfoCache(c.maxStaleness)
+	}
+
+	return c, nil
+}
+
+// GetDefaultBanCheckerConfig returns default config of the ban checker.
+func GetDefaultBanCheckerConfig() *BanCheckerConfig {
+	return &BanCheckerConfig{
+		Policy:        DegradeFailOpen,
+		MaxStaleness:  DefaultMaxStaleness,
+		StaleFallback: DegradeFailOpen,
+	}
+}
+
 func GetNewDispatcher(client SibylClient) *SibylDispatcher {
 	return &SibylDispatcher{
 		TimeoutSeconds:     DefaultDispatcherTimeout,
 		MaxConnectionTries: 50,
+		Workers:            DefaultDispatcherWorkers,
+		QueueSize:          DefaultDispatcherQueueSize,
 		sibylClient:        client,
+		mut:                &sync.Mutex{},
+		workersWg:          &sync.WaitGroup{},
 		handlers:           ssg.NewSafeMap[SibylUpdateType, []ServerUpdateHandler](),
 	}
 }
 
+// GetDefaultBackoff returns the default backoff policy of the dispatcher.
+func GetDefaultBackoff() *ExponentialBackoff {
+	return &ExponentialBackoff{
+		InitialDelay: DefaultBackoffInitialDelay,
+		MaxDelay:     DefaultBackoffMaxDelay,
+		Multiplier:   DefaultBackoffMultiplier,
+		Jitter:       DefaultBackoffJitter,
+	}
+}
+
+// IsNetworkError returns true if the error is caused by the network (e.g.
+// the connection has been refused, reset or timed out), rather than by a
+// response of the server. cancelling a context is not a network error, and
+// neither are the other errors of the http client, such as invalid urls
+// or certificates.
+func IsNetworkError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ENETUNREACH) ||
+		errors.Is(err, syscall.EHOSTUNREACH) || errors.Is(err, syscall.ETIMEDOUT) {
+		return true
+	}
+
+	// every *url.Error is a net.Error, so only its cause matters here: the
+	// connection being closed by the other side, or a failed socket call.
+	var urlErr *urlLib.Error
+	if errors.As(err, &urlErr) && errors.Is(urlErr.Err, io.EOF) {
+		return true
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
 // GetDefaultConfig returns default config.
 func GetDefaultConfig() *SibylConfig {
 	return &SibylConfig{
@@ -53,6 +164,619 @@ func GetDefaultConfig() *SibylConfig {
 	}
 }
 
+func newFlightGroup[TKey comparable, TValue any]() *flightGroup[TKey, TValue] {
+	return &flightGroup[TKey, TValue]{
+		mut:   &sync.Mutex{},
+		calls: make(map[TKey]*flightCall[TValue]),
+	}
+}
+
+func newInfoCache(ttl time.Duration) *infoCache {
+	return &infoCache{
+		mut:         &sync.RWMutex{},
+		ttl:         ttl,
+		values:      make(map[int64]*cachedInfo),
+		invalidated: make(map[int64]time.Time),
+	}
+}
+
+// loadBulkCheckpoint loads the checkpoint of a bulk operation from the given
+// path; if the path is empty, the checkpoint is only kept in the memory.
+func loadBulkCheckpoint[T any](path, operation string) (*bulkCheckpoint[T], error) {
+	checkpoint := &bulkCheckpoint[T]{
+		mut:       &sync.Mutex{},
+		path:      path,
+		Operation: operation,
+		Completed: make(map[int64]T),
+	}
+
+	if path == "" {
+		return checkpoint, nil
+	}
+
+	found, err := LoadJSONFile(path, checkpoint)
+	if err != nil {
+		return nil, err
+	} else if !found {
+		return checkpoint, nil
+	}
+
+	if checkpoint.Operation != operation {
+		return nil, ErrCheckpointMismatch
+	}
+
+	if checkpoint.Completed == nil {
+		checkpoint.Completed = make(map[int64]T)
+	}
+
+	return checkpoint, nil
+}
+
+// WriteFileAtomic writes the data to a temporary file first and then
+// renames it, so a crash in the middle of writing won't corrupt the
+// previous content of the file.
+func WriteFileAtomic(path string, data []byte) error {
+	tmpPath := path + ".tmp"
+	err := os.WriteFile(tmpPath, data, 0o644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
+
+// SaveJSONFile marshals the value to json and writes it to the file at
+// the given path using WriteFileAtomic.
+func SaveJSONFile(path string, value interface{}) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return WriteFileAtomic(path, b)
+}
+
+// LoadJSONFile unmarshals the json file at the given path into the value;
+// it returns false (and no error) if the file doesn't exist.
+func LoadJSONFile(path string, value interface{}) (bool, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, json.Unmarshal(b, value)
+}
+
+// runConcurrently calls fn for each index in [0, count), with at most
+// concurrency calls running at the same time. no new calls are made after
+// ctx is done; it returns after all of the running calls have returned.
+// runBulk runs a bulk operation on the targets: nil and repeated targets
+// fail, the targets completed in the checkpoint are skipped, and the rest
+// are done concurrently using do. the outcomes are in the same order as
+// the targets; the targets which aren't done before the context is done
+// fail with the error of the context.
+func runBulk[TTarget any, TResult any](
+	ctx context.Context,
+	opts *BulkOptions,
+	operation string,
+	targets []TTarget,
+	getUserId func(TTarget) (int64, bool),
+	do func(context.Context, TTarget) (TResult, error),
+) ([]*bulkOutcome[TResult], error) {
+	if opts == nil {
+		opts = &BulkOptions{}
+	}
+
+	checkpoint, err := loadBulkCheckpoint[TResult](opts.CheckpointPath, operation)
+	if err != nil {
+		return nil, err
+	}
+
+	outcomes := make([]*bulkOutcome[TResult], len(targets))
+	mut := &sync.Mutex{}
+	done := 0
+	finish := func(index int, outcome *bulkOutcome[TResult]) {
+		mut.Lock()
+		defer mut.Unlock()
+
+		outcomes[index] = outcome
+		done++
+		if opts.OnProgress != nil {
+			opts.OnProgress(&BulkProgress{
+				UserId: outcome.userId,
+				Done:   done,
+				Total:  len(targets),
+				Err:    outcome.err,
+			})
+		}
+	}
+
+	var pending []int
+	userIds := make([]int64, len(targets))
+	seen := make(map[int64]bool, len(targets))
+	for i, target := range targets {
+		userId, ok := getUserId(target)
+		userIds[i] = userId
+		switch {
+		case !ok:
+			finish(i, &bulkOutcome[TResult]{err: ErrNilTarget})
+			continue
+		case seen[userId]:
+			finish(i, &bulkOutcome[TResult]{userId: userId, err: ErrDuplicateTarget})
+			continue
+		}
+		seen[userId] = true
+
+		if result, ok := checkpoint.get(userId); ok {
+			finish(i, &bulkOutcome[TResult]{userId: userId, result: result, skipped: true})
+			continue
+		}
+
+		pending = append(pending, i)
+	}
+
+	runConcurrently(ctx, len(pending), opts.getConcurrency(), func(index int) {
+		i := pending[index]
+		result, err := do(ctx, targets[i])
+		outcome := &bulkOutcome[TResult]{
+			userId: userIds[i],
+			result: result,
+			err:    err,
+		}
+
+		if err == nil {
+			outcome.checkpointErr = checkpoint.add(userIds[i], result)
+		}
+
+		finish(i, outcome)
+	})
+
+	for _, i := range pending {
+		if outcomes[i] == nil {
+			outcomes[i] = &bulkOutcome[TResult]{
+				userId: userIds[i],
+				err:    ctx.Err(),
+			}
+		}
+	}
+
+	return outcomes, nil
+}
+
+func runConcurrently(ctx context.Context, count, concurrency int, fn func(index int)) {
+	indexes := make(chan int)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < concurrency && i < count; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				fn(index)
+			}
+		}()
+	}
+
+feedLoop:
+	for i := 0; i < count; i++ {
+		// select picks randomly when both are ready, so check ctx first.
+		if ctx.Err() != nil {
+			break
+		}
+
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feedLoop
+		}
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// NewSibylTime returns a new SibylTime from the given time.
+func NewSibylTime(t time.Time) SibylTime {
+	return SibylTime{Time: t}
+}
+
+// ParseSibylTime parses the given value using SibylTimeLayouts; the
+// original value is kept and used when marshalling the time.
+func ParseSibylTime(value string) (SibylTime, error) {
+	raw := []byte(strconv.Quote(value))
+	value = strings.TrimSpace(value)
+	for _, layout := range SibylTimeLayouts {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			return SibylTime{Time: t, raw: raw, parsed: t}, nil
+		}
+	}
+
+	return SibylTime{raw: raw}, ErrInvalidTime
+}
+
+// getRelativeUnit returns the biggest unit of the duration which is at least
+// one, along with the count of that unit.
+func getRelativeUnit(d time.Duration) (int64, string) {
+	switch {
+	case d >= 365*24*time.Hour:
+		return int64(d / (365 * 24 * time.Hour)), MsgKeyUnitYear
+	case d >= 30*24*time.Hour:
+		return int64(d / (30 * 24 * time.Hour)), MsgKeyUnitMonth
+	case d >= 7*24*time.Hour:
+		return int64(d / (7 * 24 * time.Hour)), MsgKeyUnitWeek
+	case d >= 24*time.Hour:
+		return int64(d / (24 * time.Hour)), MsgKeyUnitDay
+	case d >= time.Hour:
+		return int64(d / time.Hour), MsgKeyUnitHour
+	case d >= time.Minute:
+		return int64(d / time.Minute), MsgKeyUnitMinute
+	default:
+		return int64(d / time.Second), MsgKeyUnitSecond
+	}
+}
+
+// ParseBanFlag parses the given value to a registered ban flag; the value
+// is case insensitive and can have a leading '#'.
+func ParseBanFlag(value string) (BanFlag, error) {
+	flag := normalizeBanFlag(value)
+	if !banFlags.exists(flag) {
+		return flag, ErrUnknownBanFlag
+	}
+
+	return flag, nil
+}
+
+// ParseEntityType parses the given entity type, which can be either its
+// name (e.g. "bot") or its numeric value; the name is case insensitive.
+func ParseEntityType(value string) (EntityType, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if entityType, ok := entityTypeNames[value]; ok {
+		return entityType, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || !EntityType(n).IsValid() {
+		return 0, ErrInvalidEntityType
+	}
+
+	return EntityType(n), nil
+}
+
+// GetEntityTypeName returns the name of the entity type (e.g. "bot"),
+// which can be parsed back using ParseEntityType.
+func GetEntityTypeName(entityType EntityType) string {
+	for name, current := range entityTypeNames {
+		if current == entityType {
+			return name
+		}
+	}
+
+	return entityType.ToString()
+}
+
+// ParseBanFlags parses all of the given values; it stops at the first
+// unknown flag.
+func ParseBanFlags(values ...string) ([]BanFlag, error) {
+	flags := make([]BanFlag, 0, len(values))
+	for _, current := range values {
+		flag, err := ParseBanFlag(current)
+		if err != nil {
+			return nil, err
+		}
+		flags = append(flags, flag)
+	}
+
+	return flags, nil
+}
+
+// RegisterBanFlag registers a custom ban flag, so it's considered as a
+// valid flag; it returns ErrBanFlagExists if the flag already exists.
+func RegisterBanFlag(info *BanFlagInfo) error {
+	return banFlags.register(info)
+}
+
+// GetBanFlagInfo returns the metadata of the given flag, or nil if the
+// flag isn't registered.
+func GetBanFlagInfo(flag BanFlag) *BanFlagInfo {
+	return banFlags.get(flag)
+}
+
+// GetAllBanFlags returns all of the registered flags, in the order of
+// their registration.
+func GetAllBanFlags() []BanFlag {
+	return banFlags.getAll()
+}
+
+// SetBanFlagsStrictMode enables or disables the strict mode of ban flags;
+// in strict mode, marshalling or unmarshalling an unknown flag fails
+// instead of keeping it as it is.
+func SetBanFlagsStrictMode(strict bool) {
+	banFlags.setStrict(strict)
+}
+
+// IsBanFlagsStrictMode returns true if the strict mode of ban flags
+// is enabled.
+func IsBanFlagsStrictMode() bool {
+	return banFlags.isStrict()
+}
+
+// GetCrimeBand returns the crime band of the given coefficient.
+func GetCrimeBand(c CrimeCoefficient) CrimeBand {
+	switch {
+	case c >= LethalEliminatorThreshold:
+		return CrimeBandLethalEliminator
+	case c >= ParalyzerThreshold:
+		return CrimeBandParalyzer
+	case c >= LatentCriminalThreshold:
+		return CrimeBandLatentCriminal
+	default:
+		return CrimeBandNormal
+	}
+}
+
+// SetCrimeBandLabel sets the label of a crime band in the given language
+// (e.g. "ja"); the label is ignored if the language can't be parsed, use
+// SetCrimeBandLabelTag to get the error.
+func SetCrimeBandLabel(lang string, band CrimeBand, label string) {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return
+	}
+
+	_ = SetCrimeBandLabelTag(tag, band, label)
+}
+
+// SetCrimeBandLabelTag sets the label of a crime band in the given language.
+func SetCrimeBandLabelTag(tag language.Tag, band CrimeBand, label string) error {
+	key, ok := crimeBandKeys[band]
+	if !ok {
+		return ErrUnknownMessageKey
+	}
+
+	return messages.set(tag, key, catalog.String(label))
+}
+
+// RegisterLocale registers the given messages for a language; messages
+// which aren't translated fall back to the english catalog.
+func RegisterLocale(tag language.Tag, translations map[string]string) error {
+	for key, value := range translations {
+		err := messages.set(tag, key, catalog.String(value))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RegisterMessage registers a single message for a language; msg can be
+// any catalog.Message, such as plural.Selectf for the time units.
+func RegisterMessage(tag language.Tag, key string, msg ...catalog.Message) error {
+	return messages.set(tag, key, msg...)
+}
+
+// GetLocales returns the languages which have a registered message.
+func GetLocales() []language.Tag {
+	return messages.getTags()
+}
+
+// Localize returns the message of the given key in the given language,
+// formatted with the arguments.
+func Localize(tag language.Tag, key string, args ...interface{}) string {
+	return messages.sprintf(tag, key, key, args...)
+}
+
+// YesOrNoIn returns the localized "Yes" or "No".
+func YesOrNoIn(tag language.Tag, value bool) string {
+	if value {
+		return Localize(tag, MsgKeyYes)
+	}
+
+	return Localize(tag, MsgKeyNo)
+}
+
+// JoinListIn joins the items the way they're listed in the given language,
+// e.g. "a, b and c" in english.
+func JoinListIn(tag language.Tag, items []string) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
+	}
+
+	sep := Localize(tag, MsgKeyListSeparator)
+	last := Localize(tag, MsgKeyListLastSeparator)
+	return strings.Join(items[:len(items)-1], sep) + last + items[len(items)-1]
+}
+
+// FormatText escapes the raw text and applies the style to it.
+func FormatText(f Formatter, style TextStyle, text string) string {
+	return f.Style(style, f.Escape(text))
+}
+
+// FormatMention returns a mention of the user with the given raw text.
+func FormatMention(f Formatter, text string, userId int64) string {
+	return f.Link(f.Escape(text), GetUserMentionUrl(userId))
+}
+
+// GetUserMentionUrl returns the url used for mentioning a user by its id.
+func GetUserMentionUrl(userId int64) string {
+	return "tg://user?id=" + strconv.FormatInt(userId, 10)
+}
+
+// FormatFlagsWith formats the flags in mono, separated by commas.
+func FormatFlagsWith(f Formatter, flags []BanFlag) string {
+	parts := make([]string, len(flags))
+	for i, current := range flags {
+		parts[i] = FormatText(f, StyleMono, string(current))
+	}
+
+	return strings.Join(parts, f.Escape(", "))
+}
+
+// FormatCuteFlagsWith formats the names of the flags the way they're
+// listed in the given language, e.g. "spam, nsfw and raid".
+func FormatCuteFlagsWith(f Formatter, tag language.Tag, flags []BanFlag) string {
+	names := make([]string, len(flags))
+	for i, current := range flags {
+		names[i] = current.GetNameIn(tag)
+	}
+
+	return f.Escape(JoinListIn(tag, names))
+}
+
+func newMessageCatalog() *messageCatalog {
+	c := &messageCatalog{
+		mut:     &sync.RWMutex{},
+		builder: catalog.NewBuilder(catalog.Fallback(language.English)),
+		keys:    make(map[language.Tag]map[string]bool),
+	}
+
+	for key, value := range englishMessages {
+		_ = c.set(language.English, key, catalog.String(value))
+	}
+
+	for key, forms := range englishUnits {
+		_ = c.set(language.English, key, plural.Selectf(1, "%d",
+			"one", forms[0],
+			"other", forms[1],
+		))
+	}
+
+	return c
+}
+
+// NewReasonBuilder returns a new empty ReasonBuilder.
+func NewReasonBuilder() *ReasonBuilder {
+	return &ReasonBuilder{}
+}
+
+// ParseReason extracts the flags and the perma marker from the
+// given ban reason.
+func ParseReason(reason string) *ParsedReason {
+	parsed := &ParsedReason{
+		Raw: reason,
+	}
+
+	var texts []string
+	for _, word := range strings.Fields(reason) {
+		if isPermaWord(word) {
+			parsed.Perma = true
+		}
+
+		if !strings.HasPrefix(word, "#") {
+			texts = append(texts, word)
+			continue
+		}
+
+		tag := strings.TrimRightFunc(word, isReasonPunctuation)
+		flag, err := ParseBanFlag(tag)
+		if err != nil || flag == "" {
+			parsed.UnknownTags = append(parsed.UnknownTags, tag)
+			texts = append(texts, word)
+			continue
+		}
+
+		if !parsed.HasFlag(flag) {
+			parsed.Flags = append(parsed.Flags, flag)
+		}
+	}
+
+	parsed.Text = strings.Join(texts, " ")
+	return parsed
+}
+
+// ValidateReason checks the reason before it's sent to sibyl; the reason
+// can't be empty, and in strict mode (see SetBanFlagsStrictMode), it can't
+// contain hashtags which aren't known ban flags.
+func ValidateReason(reason string) error {
+	if strings.TrimSpace(reason) == "" {
+		return ErrNoReason
+	}
+
+	if banFlags.isStrict() && len(ParseReason(reason).UnknownTags) != 0 {
+		return ErrUnknownReasonFlag
+	}
+
+	return nil
+}
+
+func isPermaWord(word string) bool {
+	word = strings.TrimLeft(strings.ToLower(word), "#([")
+	return strings.HasPrefix(word, PermaMarker)
+}
+
+func isReasonPunctuation(r rune) bool {
+	return unicode.IsPunct(r) && r != '_'
+}
+
+func newBanFlagRegistry(flags ...*BanFlagInfo) *banFlagRegistry {
+	registry := &banFlagRegistry{
+		mut:   &sync.RWMutex{},
+		flags: make(map[BanFlag]*BanFlagInfo, len(flags)),
+	}
+
+	for _, current := range flags {
+		_ = registry.register(current)
+	}
+
+	return registry
+}
+
+func newUpdateDecoderRegistry(decoders map[SibylUpdateType]UpdateDecoder) *updateDecoderRegistry {
+	return &updateDecoderRegistry{
+		mut:      &sync.RWMutex{},
+		decoders: decoders,
+	}
+}
+
+// RegisterUpdateDecoder registers the decoder of a new update type, so
+// its updates are decoded by the dispatchers; it returns
+// ErrUpdateTypeExists if the type already has a decoder.
+func RegisterUpdateDecoder(uType SibylUpdateType, decoder UpdateDecoder) error {
+	return updateDecoders.register(uType, decoder)
+}
+
+// GetUpdateDecoder returns the decoder of the update type, or nil if the
+// type has no decoder.
+func GetUpdateDecoder(uType SibylUpdateType) UpdateDecoder {
+	return updateDecoders.get(uType)
+}
+
+// JsonUpdateDecoder returns a decoder which decodes the json data of the
+// updates into a new *T.
+func JsonUpdateDecoder[T any]() UpdateDecoder {
+	return func(data json.RawMessage) (any, error) {
+		value := new(T)
+		err := json.Unmarshal(data, value)
+		if err != nil {
+			return nil, err
+		}
+
+		return value, nil
+	}
+}
+
+// AddUpdateHandler adds a handler for the updates of the given type, which
+// are decoded into *T; use it for the update types added by
+// RegisterUpdateDecoder. the handler isn't called if the update has
+// another type of value, and ErrUnexpectedUpdate is passed to the
+// OnHandlerError callback instead.
+func AddUpdateHandler[T any](d *SibylDispatcher, uType SibylUpdateType, fn func(client SibylClient, update *T) error) {
+	d.AddHandler(uType, func(client SibylClient, ctx *SibylUpdateContext) error {
+		update, ok := ctx.Value.(*T)
+		if !ok {
+			return ErrUnexpectedUpdate
+		}
+
+		return fn(client, update)
+	})
+}
+
+func normalizeBanFlag(value string) BanFlag {
+	return BanFlag(strings.ToUpper(strings.TrimPrefix(strings.TrimSpace(value), "#")))
+}
+
 func ToSibylError(err error) *SibylError {
 	if err == nil {
 		return nil
@@ -65,6 +789,148 @@ func ToSibylError(err error) *SibylError {
 	return nil
 }
 
+// decodeBansStream decodes a getBans response from r, calling fn for each
+// of the banned users as soon as it's decoded.
+func decodeBansStream(r io.Reader, fn func(info *BanInfo) bool) error {
+	decoder := json.NewDecoder(r)
+	err := expectDelim(decoder, '{')
+	if err != nil {
+		return err
+	}
+
+	var success, hasResult bool
+	var sibylErr *SibylError
+	for decoder.More() {
+		var key string
+		key, err = readKey(decoder)
+		if err != nil {
+			return err
+		}
+
+		switch key {
+		case "success":
+			err = decoder.Decode(&success)
+		case "error":
+			err = decoder.Decode(&sibylErr)
+		case "result":
+			hasResult, err = decodeBansResult(decoder, fn)
+		default:
+			err = decoder.Decode(new(json.RawMessage))
+		}
+
+		if err == errStopStreaming {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if !success && sibylErr != nil {
+		return sibylErr
+	}
+
+	if !hasResult {
+		return ErrInvalidResp
+	}
+
+	return nil
+}
+
+// decodeBansResult decodes the "result" field of a getBans response; it
+// returns false if the result is null.
+func decodeBansResult(decoder *json.Decoder, fn func(info *BanInfo) bool) (bool, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return false, err
+	}
+
+	if token == nil {
+		return false, nil
+	}
+
+	if token != json.Delim('{') {
+		return false, ErrInvalidResp
+	}
+
+	for decoder.More() {
+		var key string
+		key, err = readKey(decoder)
+		if err != nil {
+			return false, err
+		}
+
+		if key != "users" {
+			err = decoder.Decode(new(json.RawMessage))
+			if err != nil {
+				return false, err
+			}
+			continue
+		}
+
+		err = decodeBansUsers(decoder, fn)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return true, expectDelim(decoder, '}')
+}
+
+func decodeBansUsers(decoder *json.Decoder, fn func(info *BanInfo) bool) error {
+	token, err := decoder.Token()
+	if err != nil || token == nil {
+		return err
+	}
+
+	if token != json.Delim('[') {
+		return ErrInvalidResp
+	}
+
+	// the same value is reused for all of the users, so only one of them
+	// is alive at any time.
+	info := new(BanInfo)
+	for decoder.More() {
+		*info = BanInfo{}
+		err = decoder.Decode(info)
+		if err != nil {
+			return err
+		}
+
+		if !fn(info) {
+			return errStopStreaming
+		}
+	}
+
+	return expectDelim(decoder, ']')
+}
+
+func readKey(decoder *json.Decoder) (string, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return "", err
+	}
+
+	key, ok := token.(string)
+	if !ok {
+		return "", ErrInvalidResp
+	}
+
+	return key, nil
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	if token != delim {
+		return ErrInvalidResp
+	}
+
+	return nil
+}
+
 func validateHostUrl(value string) string {
 	if len(value) < 3 {
 		return DefaultUrl
@@ -9,15 +9,23 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"html"
 	"io"
+	"math"
+	"math/rand"
 	"net/http"
 	urlLib "net/url"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ALiwoto/mdparser/mdparser"
 	ws "github.com/AnimeKaizoku/ssg/ssg"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
 )
 
 // error methods:
@@ -30,7 +38,20 @@ func (e *SibylError) Error() string {
 
 // general and private methods:
 
+func (s *sibylCore) waitForLimiter(ctx context.Context) error {
+	if s.RateLimiter == nil {
+		return nil
+	}
+
+	return s.RateLimiter.Wait(ctx)
+}
+
 func (s *sibylCore) revokeRequest(req *http.Request, result interface{}) error {
+	err := s.waitForLimiter(req.Context())
+	if err != nil {
+		return err
+	}
+
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return err
@@ -56,13 +77,22 @@ func (s *sibylCore) readResp(resp *http.Response, result interface{}) error {
 }
 
 func (s *sibylCore) getRequest(url string, params urlLib.Values, result interface{}) error {
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	return s.getRequestWithContext(context.Background(), url, params, result)
+}
+
+func (s *sibylCore) getRequestWithContext(ctx context.Context, url string, params urlLib.Values, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return err
 	}
 
 	req.URL.RawQuery = params.Encode()
 
+	err = s.waitForLimiter(req.Context())
+	if err != nil {
+		return err
+	}
+
 	resp, err := s.HttpClient.Do(req)
 	if err != nil {
 		return err
@@ -94,6 +124,7 @@ func (s *sibylCore) ChangeToken(token string) error {
 		return ErrInvalidToken
 	}
 	s.Token = token
+	s.infoCache.clear()
 	return nil
 }
 
@@ -120,8 +151,12 @@ func (s *sibylCore) GetHostUrl() string {
 // ban-related methods:
 
 func (s *sibylCore) Ban(userId int64, reason string, config *BanConfig) (*BanResult, error) {
-	if len(reason) < 1 {
-		return nil, ErrNoReason
+	return s.BanWithContext(context.Background(), userId, reason, config)
+}
+
+func (s *sibylCore) BanWithContext(ctx context.Context, userId int64, reason string, config *BanConfig) (*BanResult, error) {
+	if err := ValidateReason(reason); err != nil {
+		return nil, err
 	}
 
 	if config == nil {
@@ -146,7 +181,7 @@ func (s *sibylCore) Ban(userId int64, reason string, config *BanConfig) (*BanRes
 
 	resp := new(AddBanResponse)
 
-	err := s.getRequest(s.HostUrl+"addBan", v, resp)
+	err := s.getRequestWithContext(ctx, s.HostUrl+"addBan", v, resp)
 	if err != nil {
 		return nil, err
 	}
@@ -155,6 +190,7 @@ func (s *sibylCore) Ban(userId int64, reason string, config *BanConfig) (*BanRes
 		return nil, resp.Error
 	}
 
+	s.infoCache.delete(userId)
 	return resp.Result, nil
 }
 
@@ -177,7 +213,11 @@ func (s *sibylCore) BanBot(userId int64, reason string, config *BanConfig) (*Ban
 }
 
 func (s *sibylCore) RemoveBan(userId int64, reason string, config *RevertConfig) (string, error) {
-	req, err := http.NewRequest(http.MethodGet, s.HostUrl+"remBan", nil)
+	return s.RemoveBanWithContext(context.Background(), userId, reason, config)
+}
+
+func (s *sibylCore) RemoveBanWithContext(ctx context.Context, userId int64, reason string, config *RevertConfig) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.HostUrl+"remBan", nil)
 	if err != nil {
 		return "", err
 	}
@@ -211,6 +251,7 @@ func (s *sibylCore) RemoveBan(userId int64, reason string, config *RevertConfig)
 		return "", resp.Error
 	}
 
+	s.infoCache.delete(userId)
 	return resp.Result, nil
 }
 
@@ -218,6 +259,66 @@ func (s *sibylCore) RevertBan(userId int64, reason string, config *RevertConfig)
 	return s.RemoveBan(userId, reason, config)
 }
 
+func (s *sibylCore) BanMany(ctx context.Context, targets []*BanTarget, opts *BulkOptions) (*BanManyReport, error) {
+	if ctx == nil {
+		ctx = s.Context
+	}
+
+	outcomes, err := runBulk(ctx, opts, bulkOperationBan, targets, (*BanTarget).getUserId,
+		func(ctx context.Context, target *BanTarget) (*BanResult, error) {
+			return s.BanWithContext(ctx, target.UserId, target.GetReason(), target.Config)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &BanManyReport{
+		Outcomes: make([]*BanOutcome, len(outcomes)),
+	}
+	for i, current := range outcomes {
+		report.Outcomes[i] = &BanOutcome{
+			UserId:        current.userId,
+			Result:        current.result,
+			Err:           current.err,
+			Skipped:       current.skipped,
+			CheckpointErr: current.checkpointErr,
+		}
+		report.add(report.Outcomes[i])
+	}
+
+	return report, ctx.Err()
+}
+
+func (s *sibylCore) RemoveBanMany(ctx context.Context, targets []*RevertTarget, opts *BulkOptions) (*RemoveBanManyReport, error) {
+	if ctx == nil {
+		ctx = s.Context
+	}
+
+	outcomes, err := runBulk(ctx, opts, bulkOperationRevert, targets, (*RevertTarget).getUserId,
+		func(ctx context.Context, target *RevertTarget) (string, error) {
+			return s.RemoveBanWithContext(ctx, target.UserId, target.Reason, target.Config)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RemoveBanManyReport{
+		Outcomes: make([]*RevertOutcome, len(outcomes)),
+	}
+	for i, current := range outcomes {
+		report.Outcomes[i] = &RevertOutcome{
+			UserId:        current.userId,
+			Result:        current.result,
+			Err:           current.err,
+			Skipped:       current.skipped,
+			CheckpointErr: current.checkpointErr,
+		}
+		report.add(report.Outcomes[i])
+	}
+
+	return report, ctx.Err()
+}
+
 func (s *sibylCore) FullRevert(userId int64, config *FullRevertConfig) (string, error) {
 	req, err := http.NewRequest(http.MethodGet, s.HostUrl+"fullRevert", nil)
 	if err != nil {
@@ -250,18 +351,137 @@ func (s *sibylCore) FullRevert(userId int64, config *FullRevertConfig) (string,
 		return "", resp.Error
 	}
 
+	s.infoCache.delete(userId)
 	return resp.Result, nil
 }
 
 // info methods:
 
 func (s *sibylCore) GetInfo(userId int64) (*GetInfoResult, error) {
-	req, err := http.NewRequest(http.MethodGet, s.HostUrl+"getInfo", nil)
+	return s.GetInfoWithContext(s.Context, userId)
+}
+
+func (s *sibylCore) GetInfoWithContext(ctx context.Context, userId int64) (*GetInfoResult, error) {
+	if cached := s.infoCache.get(userId); cached != nil {
+		return cached.clone(), nil
+	}
+
+	key := flightKey{token: s.Token, userId: userId}
+	result, err := s.infoFlight.do(ctx, s.Context, key, func(fCtx context.Context) (*GetInfoResult, error) {
+		requestedAt := time.Now()
+		info, err := s.getInfo(fCtx, key.token, userId)
+		if err == nil && info != nil {
+			s.infoCache.set(userId, info, requestedAt)
+		}
+		return info, err
+	})
+
+	return result.clone(), err
+}
+
+func (s *sibylCore) GetInfoMany(ctx context.Context, ids []int64, opts *GetInfoManyOptions) (*GetInfoManyResult, error) {
+	if ctx == nil {
+		ctx = s.Context
+	}
+
+	if opts == nil {
+		opts = &GetInfoManyOptions{}
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBulkConcurrency
+	}
+
+	result := &GetInfoManyResult{
+		Results: make(map[int64]*GetInfoResult, len(ids)),
+		Errors:  make(map[int64]error),
+	}
+
+	// resolve the cached ids first, so the caller gets them without waiting
+	// for the rest of the requests.
+	var pending []int64
+	seen := make(map[int64]bool, len(ids))
+	for _, userId := range ids {
+		if seen[userId] {
+			continue
+		}
+		seen[userId] = true
+
+		if cached := s.infoCache.get(userId); cached != nil {
+			result.Results[userId] = cached.clone()
+			result.CachedCount++
+			continue
+		}
+
+		pending = append(pending, userId)
+	}
+
+	total := len(seen)
+	done := 0
+	for userId := range result.Results {
+		done++
+		if opts.OnProgress != nil {
+			opts.OnProgress(&BulkProgress{
+				UserId:    userId,
+				Done:      done,
+				Total:     total,
+				FromCache: true,
+			})
+		}
+	}
+
+	mut := &sync.Mutex{}
+	runConcurrently(ctx, len(pending), concurrency, func(index int) {
+		userId := pending[index]
+		info, err := s.GetInfoWithContext(ctx, userId)
+		if err != nil && ctx.Err() != nil {
+			// cancelled ids are neither resolved nor failed.
+			return
+		}
+
+		mut.Lock()
+		defer mut.Unlock()
+
+		if err != nil {
+			result.Errors[userId] = err
+		} else {
+			result.Results[userId] = info
+		}
+		done++
+		if opts.OnProgress != nil {
+			opts.OnProgress(&BulkProgress{
+				UserId: userId,
+				Done:   done,
+				Total:  total,
+				Err:    err,
+			})
+		}
+	})
+
+	return result, ctx.Err()
+}
+
+func (s *sibylCore) ClearInfoCache() {
+	s.infoCache.clear()
+}
+
+func (s *sibylCore) GetCachedInfo(userId int64) (*GetInfoResult, time.Time) {
+	value := s.infoCache.getValue(userId)
+	if value == nil {
+		return nil, time.Time{}
+	}
+
+	return value.info.clone(), value.cachedAt
+}
+
+func (s *sibylCore) getInfo(ctx context.Context, token string, userId int64) (*GetInfoResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.HostUrl+"getInfo", nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Add("token", s.Token)
+	req.Header.Add("token", token)
 	req.Header.Add("user-id", strconv.FormatInt(userId, 10))
 
 	resp := new(GetInfoResponse)
@@ -278,12 +498,25 @@ func (s *sibylCore) GetInfo(userId int64) (*GetInfoResult, error) {
 }
 
 func (s *sibylCore) GetGeneralInfo(userId int64) (*GeneralInfoResult, error) {
-	req, err := http.NewRequest(http.MethodGet, s.HostUrl+"getGeneralInfo", nil)
+	return s.GetGeneralInfoWithContext(s.Context, userId)
+}
+
+func (s *sibylCore) GetGeneralInfoWithContext(ctx context.Context, userId int64) (*GeneralInfoResult, error) {
+	key := flightKey{token: s.Token, userId: userId}
+	result, err := s.generalInfoFlight.do(ctx, s.Context, key, func(fCtx context.Context) (*GeneralInfoResult, error) {
+		return s.getGeneralInfo(fCtx, key.token, userId)
+	})
+
+	return ws.Clone(result), err
+}
+
+func (s *sibylCore) getGeneralInfo(ctx context.Context, token string, userId int64) (*GeneralInfoResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.HostUrl+"getGeneralInfo", nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Add("token", s.Token)
+	req.Header.Add("token", token)
 	req.Header.Add("user-id", strconv.FormatInt(userId, 10))
 
 	resp := new(GeneralInfoResponse)
@@ -320,13 +553,52 @@ func (s *sibylCore) GetGetAllBannedUsers() (*GetBansResult, error) {
 	return resp.Result, nil
 }
 
+func (s *sibylCore) StreamBannedUsers(ctx context.Context, fn func(info *BanInfo) bool) error {
+	if ctx == nil {
+		ctx = s.Context
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.HostUrl+"getBans", nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Add("token", s.Token)
+
+	err = s.waitForLimiter(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return decodeBansStream(resp.Body, fn)
+}
+
 func (s *sibylCore) GetStats() (*GetStatsResult, error) {
-	req, err := http.NewRequest(http.MethodGet, s.HostUrl+"getStats", nil)
+	return s.GetStatsWithContext(s.Context)
+}
+
+func (s *sibylCore) GetStatsWithContext(ctx context.Context) (*GetStatsResult, error) {
+	key := flightKey{token: s.Token}
+	result, err := s.statsFlight.do(ctx, s.Context, key, func(fCtx context.Context) (*GetStatsResult, error) {
+		return s.getStats(fCtx, key.token)
+	})
+
+	return ws.Clone(result), err
+}
+
+func (s *sibylCore) getStats(ctx context.Context, token string) (*GetStatsResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.HostUrl+"getStats", nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Add("token", s.Token)
+	req.Header.Add("token", token)
 
 	resp := new(GetStatsResponse)
 
@@ -364,8 +636,8 @@ func (s *sibylCore) CheckToken() (bool, error) {
 
 // report methods:
 func (s *sibylCore) Report(userId int64, reason string, config *ReportConfig) (string, error) {
-	if len(reason) < 1 {
-		return "", ErrNoReason
+	if err := ValidateReason(reason); err != nil {
+		return "", err
 	}
 
 	if config == nil {
@@ -501,12 +773,25 @@ func (s *sibylCore) RevokeToken(userId int64) (*TokenInfo, error) {
 }
 
 func (s *sibylCore) GetToken(userId int64) (*TokenInfo, error) {
-	req, err := http.NewRequest(http.MethodGet, s.HostUrl+"getToken", nil)
+	return s.GetTokenWithContext(s.Context, userId)
+}
+
+func (s *sibylCore) GetTokenWithContext(ctx context.Context, userId int64) (*TokenInfo, error) {
+	key := flightKey{token: s.Token, userId: userId}
+	result, err := s.tokenFlight.do(ctx, s.Context, key, func(fCtx context.Context) (*TokenInfo, error) {
+		return s.getToken(fCtx, key.token, userId)
+	})
+
+	return ws.Clone(result), err
+}
+
+func (s *sibylCore) getToken(ctx context.Context, token string, userId int64) (*TokenInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.HostUrl+"getToken", nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Add("token", s.Token)
+	req.Header.Add("token", token)
 	req.Header.Add("user-id", strconv.FormatInt(userId, 10))
 
 	resp := new(GetTokenResponse)
@@ -523,7 +808,11 @@ func (s *sibylCore) GetToken(userId int64) (*TokenInfo, error) {
 }
 
 func (s *sibylCore) StartPolling() (*PollingIdentifier, error) {
-	req, err := http.NewRequest(http.MethodGet, s.HostUrl+"startPolling", nil)
+	return s.StartPollingWithContext(s.Context)
+}
+
+func (s *sibylCore) StartPollingWithContext(ctx context.Context) (*PollingIdentifier, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.HostUrl+"startPolling", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -544,7 +833,15 @@ func (s *sibylCore) StartPolling() (*PollingIdentifier, error) {
 }
 
 func (s *sibylCore) GetUpdates(timeout int, pollingId *PollingIdentifier) (*ServerUpdateContainer, error) {
-	req, err := http.NewRequest(http.MethodGet, s.HostUrl+"getUpdates", nil)
+	return s.GetUpdatesWithContext(s.Context, timeout, pollingId)
+}
+
+func (s *sibylCore) GetUpdatesWithContext(
+	ctx context.Context,
+	timeout int,
+	pollingId *PollingIdentifier,
+) (*ServerUpdateContainer, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.HostUrl+"getUpdates", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -626,6 +923,26 @@ func (t *TokenInfo) IsOwner() bool {
 
 //---------------------------------------------------------
 
+// GetName returns the english name of the permission.
+func (p UserPermission) GetName() string {
+	return p.GetNameIn(language.English)
+}
+
+// GetNameIn returns the name of the permission in the given language.
+func (p UserPermission) GetNameIn(tag language.Tag) string {
+	key, ok := permissionKeys[p]
+	if !ok {
+		key = MsgKeyUnknown
+	}
+
+	return Localize(tag, key)
+}
+
+// FormatWith formats the name of the permission in the given language.
+func (p UserPermission) FormatWith(f Formatter, tag language.Tag) string {
+	return f.Escape(p.GetNameIn(tag))
+}
+
 // IsOwner returns true if the token's permission
 // is owner.
 func (p UserPermission) IsOwner() bool {
@@ -748,8 +1065,46 @@ func (p UserPermission) CanGetStats() bool {
 
 //---------------------------------------------------------
 
+// ToBanInfo converts the result to a BanInfo, so it can be used by the
+// helpers working on ban records.
+func (r *GetInfoResult) ToBanInfo() *BanInfo {
+	return &BanInfo{
+		UserId:           r.UserId,
+		Banned:           r.Banned,
+		Reason:           r.Reason,
+		Message:          r.Message,
+		BanSourceUrl:     r.BanSourceUrl,
+		BannedBy:         r.BannedBy,
+		CrimeCoefficient: r.CrimeCoefficient,
+		Date:             r.Date,
+		BanFlags:         append([]BanFlag(nil), r.BanFlags...),
+		TargetType:       r.TargetType,
+	}
+}
+
+// clone returns a copy of the result which doesn't share anything with
+// it, so the results kept in the cache can't be changed by the callers.
+func (r *GetInfoResult) clone() *GetInfoResult {
+	if r == nil {
+		return nil
+	}
+
+	cloned := *r
+	if r.BanFlags != nil {
+		cloned.BanFlags = append([]BanFlag(nil), r.BanFlags...)
+	}
+
+	return &cloned
+}
+
+// IsPerma returns true if the reason of the ban marks it as permanent.
 func (r *GetInfoResult) IsPerma() bool {
-	return strings.Contains(r.Reason, "perma")
+	return ParseReason(r.Reason).Perma
+}
+
+// ParseReason parses the reason of the ban.
+func (r *GetInfoResult) ParseReason() *ParsedReason {
+	return ParseReason(r.Reason)
 }
 
 func (r *GetInfoResult) HasCustomFlag() bool {
@@ -761,20 +1116,43 @@ func (r *GetInfoResult) SetAsBanReason(reason string) {
 }
 
 func (r *GetInfoResult) GetDateAsShort() string {
-	return r.Date
+	return r.Date.FormatShort()
 }
 
 func (r *GetInfoResult) EstimateCrimeCoefficient() string {
-	c := r.CrimeCoefficient
-	if c > 100 {
-		str := strconv.Itoa(c)
-		return "over " + str[:len(str)-2] + "00"
-	}
-	return "under 100"
+	return r.CrimeCoefficient.Estimate()
+}
+
+// EstimateCrimeCoefficientIn is the same as EstimateCrimeCoefficient,
+// in the given language.
+func (r *GetInfoResult) EstimateCrimeCoefficientIn(tag language.Tag) string {
+	return r.CrimeCoefficient.EstimateIn(tag)
 }
 
 func (r *GetInfoResult) GetStringCrimeCoefficient() string {
-	return strconv.Itoa(r.CrimeCoefficient)
+	return r.CrimeCoefficient.String()
+}
+
+// GetCrimeBand returns the crime band of the user.
+func (r *GetInfoResult) GetCrimeBand() CrimeBand {
+	return r.CrimeCoefficient.GetBand()
+}
+
+// FormatFlagsWith is the same as FormatFlags, using the given formatter.
+func (r *GetInfoResult) FormatFlagsWith(f Formatter) string {
+	return FormatFlagsWith(f, r.BanFlags)
+}
+
+// FormatCuteFlagsWith is the same as FormatCuteFlagsIn, using the
+// given formatter.
+func (r *GetInfoResult) FormatCuteFlagsWith(f Formatter, tag language.Tag) string {
+	return FormatCuteFlagsWith(f, tag, r.BanFlags)
+}
+
+// FormatEstimateWith formats the estimated crime coefficient of the user,
+// using the given formatter.
+func (r *GetInfoResult) FormatEstimateWith(f Formatter, tag language.Tag) string {
+	return r.CrimeCoefficient.FormatEstimateWith(f, tag)
 }
 
 func (r *GetInfoResult) FormatFlags() mdparser.WMarkDown {
@@ -794,211 +1172,1332 @@ func (r *GetInfoResult) FormatFlags() mdparser.WMarkDown {
 }
 
 func (r *GetInfoResult) FormatCuteFlags() mdparser.WMarkDown {
+	return r.FormatCuteFlagsIn(language.English)
+}
+
+// FormatCuteFlagsIn is the same as FormatCuteFlags, in the given language.
+func (r *GetInfoResult) FormatCuteFlagsIn(tag language.Tag) mdparser.WMarkDown {
 	md := mdparser.GetEmpty()
 	if len(r.BanFlags) == 0 {
 		return md
 	} else if len(r.BanFlags) == 1 {
-		return md.Normal(strings.ToLower(string(r.BanFlags[0x0])))
+		return md.Normal(r.BanFlags[0x0].GetNameIn(tag))
 	}
 
 	for i, current := range r.BanFlags {
 		if i != 0 && i != len(r.BanFlags)-1 {
-			md.Normal(", ")
+			md.Normal(Localize(tag, MsgKeyListSeparator))
 		} else if i == len(r.BanFlags)-1 {
-			md.Normal(" and ")
+			md.Normal(Localize(tag, MsgKeyListLastSeparator))
 		}
-		md.Normal(strings.ToLower(string(current)))
+		md.Normal(current.GetNameIn(tag))
 	}
 
 	return md
 }
 
 func (r *GetInfoResult) EstimateCrimeCoefficientSep() (string, string) {
-	c := r.CrimeCoefficient
-	if c > 100 {
-		str := strconv.Itoa(c)
-		return "over ", str[:len(str)-2] + "00"
-	}
-	return "under ", "100"
+	return r.CrimeCoefficient.EstimateSep()
 }
 
-//---------------------------------------------------------
-
-func (d *SibylDispatcher) Listen() {
-	d.totalTries = 0
-	go d.StartListening()
+// EstimateCrimeCoefficientSepIn is the same as EstimateCrimeCoefficientSep,
+// in the given language.
+func (r *GetInfoResult) EstimateCrimeCoefficientSepIn(tag language.Tag) (string, string) {
+	return r.CrimeCoefficient.EstimateSepIn(tag)
 }
 
-func (d *SibylDispatcher) StartListening() {
-	d.totalTries++
-	if d.totalTries > d.MaxConnectionTries {
-		// give up
-		return
-	}
-
-	for !d.isStopped {
-		var err error
-		d.PollingId, err = d.sibylClient.StartPolling()
-		if err != nil {
-			if d.onStartFailed != nil {
-				d.onStartFailed(err)
-			}
-
-			time.Sleep(2 * time.Second)
-			continue
-		}
-
-		var container *ServerUpdateContainer
-
-		for !d.isStopped {
-			container, err = d.sibylClient.GetUpdates(d.TimeoutSeconds, d.PollingId)
-			if err != nil {
-				errStr := err.Error()
-				if strings.Contains(errStr, "dial tcp") || strings.Contains(errStr, "connect: connection refused") {
-					// connection issue, try to reconnect.
-					break
-				}
-
-				if d.onGetUpdateFailed != nil {
-					d.onGetUpdateFailed(err)
-				}
+//---------------------------------------------------------
 
-				continue
-			}
+// FormatEstimateWith formats the estimation of the coefficient with the
+// value in mono, e.g. "over `200`".
+func (c CrimeCoefficient) FormatEstimateWith(f Formatter, tag language.Tag) string {
+	prefix, value := c.EstimateSepIn(tag)
+	return f.Escape(prefix) + FormatText(f, StyleMono, value)
+}
 
-			// no updates, our request got timed out
-			if container == nil {
-				continue
-			}
+// GetBand returns the crime band of the coefficient.
+func (c CrimeCoefficient) GetBand() CrimeBand {
+	return GetCrimeBand(c)
+}
 
-			// parse and handle each update in its own goroutine, to get the
-			// best performance.
-			go d.onUpdateReceived(container)
-		}
-	}
+// GetAction returns the enforcement action suggested for the coefficient.
+func (c CrimeCoefficient) GetAction() EnforcementAction {
+	return GetCrimeBand(c).GetAction()
 }
 
-func (d *SibylDispatcher) SetOnStartFailed(fn func(error)) {
-	d.onStartFailed = fn
+// IsTarget returns true if the coefficient is high enough for
+// enforcement action.
+func (c CrimeCoefficient) IsTarget() bool {
+	return c >= LatentCriminalThreshold
 }
 
-func (d *SibylDispatcher) SetOnGetUpdateFailed(fn func(error)) {
-	d.onGetUpdateFailed = fn
+// Estimate returns an estimation of the coefficient rounded down to
+// hundreds, such as "under 100", "over 200" or "exactly 300".
+func (c CrimeCoefficient) Estimate() string {
+	return c.EstimateIn(language.English)
 }
 
-func (d *SibylDispatcher) SetOnHandlerError(fn func(error)) {
-	d.onHandlerError = fn
+// EstimateIn is the same as Estimate, in the given language.
+func (c CrimeCoefficient) EstimateIn(tag language.Tag) string {
+	prefix, value := c.EstimateSepIn(tag)
+	return prefix + value
 }
 
-func (d *SibylDispatcher) AddHandler(uType SibylUpdateType, h ServerUpdateHandler) {
-	handlers := d.handlers.GetValue(uType)
-	handlers = append(handlers, h)
-	d.handlers.Set(uType, handlers)
+// EstimateSep is the same as Estimate, with the prefix and the value
+// returned separately.
+func (c CrimeCoefficient) EstimateSep() (string, string) {
+	return c.EstimateSepIn(language.English)
 }
 
-func (d *SibylDispatcher) onUpdateReceived(container *ServerUpdateContainer) {
-	var err error
-	ctx := new(SibylUpdateContext)
-	switch container.UpdateType {
-	case UpdateTypeScanRequestApproved:
-		ctx.ScanRequestApproved = new(ScanRequestApprovedUpdate)
-		err = json.Unmarshal(container.UpdateData, ctx.ScanRequestApproved)
-	case UpdateTypeScanRequestRejected:
-		ctx.ScanRequestRejected = new(ScanRequestApprovedUpdate)
-		err = json.Unmarshal(container.UpdateData, ctx.ScanRequestRejected)
-	default:
-		// #TODO: add something for capturing this in future, idk
-		return
+// EstimateSepIn is the same as EstimateSep, in the given language.
+func (c CrimeCoefficient) EstimateSepIn(tag language.Tag) (string, string) {
+	if c < LatentCriminalThreshold {
+		return Localize(tag, MsgKeyEstimateUnder), "100"
 	}
 
-	if err != nil {
-		if d.onGetUpdateFailed != nil {
-			d.onGetUpdateFailed(err)
-			return
-		}
+	rounded := c / 100 * 100
+	if c == rounded {
+		return Localize(tag, MsgKeyEstimateExactly), rounded.String()
 	}
 
-	handlers := d.handlers.GetValue(container.UpdateType)
-	if len(handlers) == 0 {
-		return
-	}
+	return Localize(tag, MsgKeyEstimateOver), rounded.String()
+}
 
-	for _, current := range handlers {
-		err = current(d.sibylClient, ctx)
-		if err != nil && d.onHandlerError != nil {
-			d.onHandlerError(err)
-		}
-	}
+func (c CrimeCoefficient) String() string {
+	return strconv.FormatInt(int64(c), 10)
 }
 
 //---------------------------------------------------------
 
-func (e EntityType) ToString() string {
-	return ws.ToBase10(int64(e))
+// GetAction returns the enforcement action suggested for the band.
+func (b CrimeBand) GetAction() EnforcementAction {
+	switch b {
+	case CrimeBandLatentCriminal:
+		return ActionWarn
+	case CrimeBandParalyzer:
+		return ActionMute
+	case CrimeBandLethalEliminator:
+		return ActionBan
+	default:
+		return ActionNone
+	}
 }
 
-func (e EntityType) IsBot() bool {
-	return e == EntityTypeBot
+// GetLabel returns the english label of the band.
+func (b CrimeBand) GetLabel() string {
+	return b.GetLabelInTag(language.English)
 }
 
-func (e EntityType) IsBotStr() string {
-	return ws.YesOrNo(e == EntityTypeBot)
-}
+// GetLabelIn returns the label of the band in the given language (e.g.
+// "ja"); it falls back to english if the language can't be parsed or has
+// no label for the band.
+func (b CrimeBand) GetLabelIn(lang string) string {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		tag = language.English
+	}
 
-func (e EntityType) IsUser() bool {
-	return e == EntityTypeUser
+	return b.GetLabelInTag(tag)
 }
 
-func (e EntityType) IsUserStr() string {
-	return ws.YesOrNo(e == EntityTypeUser)
-}
+// GetLabelInTag returns the label of the band in the given language; it
+// falls back to english if the language has no label for the band.
+func (b CrimeBand) GetLabelInTag(tag language.Tag) string {
+	key, ok := crimeBandKeys[b]
+	if !ok {
+		key = MsgKeyBandUnknown
+	}
 
-func (e EntityType) IsAdmin() bool {
-	return e == EntityTypeAdmin
+	return Localize(tag, key)
 }
 
-func (e EntityType) IsAdminStr() string {
-	return ws.YesOrNo(e == EntityTypeAdmin)
+func (b CrimeBand) String() string {
+	return b.GetLabel()
 }
 
-func (e EntityType) IsOwner() bool {
-	return e == EntityTypeOwner
-}
+//---------------------------------------------------------
 
-func (e EntityType) IsOwnerStr() string {
-	return ws.YesOrNo(e == EntityTypeOwner)
+func (a EnforcementAction) String() string {
+	switch a {
+	case ActionNone:
+		return "none"
+	case ActionWarn:
+		return "warn"
+	case ActionMute:
+		return "mute"
+	case ActionBan:
+		return "ban"
+	default:
+		return "unknown"
+	}
 }
 
-func (e EntityType) IsGroup() bool {
-	return e == EntityTypeGroup
-}
+//---------------------------------------------------------
 
-func (e EntityType) IsGroupStr() string {
-	return ws.YesOrNo(e == EntityTypeGroup)
-}
+// Listen starts listening to the updates in a new goroutine; use Stop or
+// Shutdown to stop it. it returns ErrDispatcherRunning if the dispatcher
+// is already running. the error which the loop stops with (e.g. a
+// *GiveUpError) is passed to the OnGiveUp callback, and can be checked
+// using GetLastError after the dispatcher has stopped.
+func (d *SibylDispatcher) Listen() error {
+	ctx, err := d.start(context.Background())
+	if err != nil {
+		return err
+	}
 
-func (e EntityType) IsChannel() bool {
-	return e == EntityTypeChannel
+	go d.run(context.Background(), ctx)
+	return nil
 }
 
-func (e EntityType) IsChannelStr() string {
-	return ws.YesOrNo(e == EntityTypeChannel)
+// StartListening listens to the updates until the dispatcher is stopped;
+// use Run to get the error which the dispatcher stops with.
+func (d *SibylDispatcher) StartListening() {
+	_ = d.Run(context.Background())
 }
 
-func (e EntityType) IsChat() bool {
-	return e == EntityTypeChannel || e == EntityTypeGroup
-}
+// Run listens to the updates until the context is done or the dispatcher
+// is stopped, then waits for the running handlers and returns. it returns
+// the error of the context, or nil if the dispatcher has been stopped.
+func (d *SibylDispatcher) Run(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	loopCtx, err := d.start(ctx)
+	if err != nil {
+		return err
+	}
+
+	return d.run(ctx, loopCtx)
+}
+
+// Stop stops the dispatcher, cancelling the in-flight requests; it doesn't
+// wait for the running handlers, use Shutdown for that.
+func (d *SibylDispatcher) Stop() {
+	d.mut.Lock()
+	if d.cancel != nil {
+		d.cancel()
+	}
+	d.mut.Unlock()
+}
+
+// Shutdown stops the dispatcher and waits for the running handlers to
+// return, or for the context to be done; in the latter case, the error
+// of the context is returned.
+func (d *SibylDispatcher) Shutdown(ctx context.Context) error {
+	d.mut.Lock()
+	running := d.running
+	doneChan := d.doneChan
+	if d.cancel != nil {
+		d.cancel()
+	}
+	d.mut.Unlock()
+
+	if !running {
+		return nil
+	}
+
+	select {
+	case <-doneChan:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// IsRunning returns true if the dispatcher is listening to the updates,
+// or waiting for its handlers to return.
+func (d *SibylDispatcher) IsRunning() bool {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+
+	return d.running
+}
+
+// GetLastError returns the error which the last run of the dispatcher
+// stopped with, e.g. a *GiveUpError; it's nil if the dispatcher has been
+// stopped normally, or hasn't stopped yet.
+func (d *SibylDispatcher) GetLastError() error {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+
+	return d.lastErr
+}
+
+// start marks the dispatcher as running and returns the context of the
+// loop, which is cancelled by Stop.
+func (d *SibylDispatcher) start(ctx context.Context) (context.Context, error) {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+
+	if d.running {
+		return nil, ErrDispatcherRunning
+	}
+
+	loopCtx, cancel := context.WithCancel(ctx)
+	d.running = true
+	d.cancel = cancel
+	d.doneChan = make(chan struct{})
+	d.lastErr = nil
+
+	return loopCtx, nil
+}
+
+func (d *SibylDispatcher) run(ctx, loopCtx context.Context) error {
+	queues := d.startWorkers()
+	err := d.listen(loopCtx, queues)
+
+	// the updates already in the queues are still handled.
+	for _, current := range queues {
+		close(current)
+	}
+	d.workersWg.Wait()
+
+	d.mut.Lock()
+	d.cancel()
+	d.running = false
+	d.cancel = nil
+	d.lastErr = err
+	close(d.doneChan)
+	d.mut.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.Err()
+}
+
+// listen gets the updates until the context is done; it returns a
+// *GiveUpError if the dispatcher gives up connecting to the server.
+func (d *SibylDispatcher) listen(ctx context.Context, queues []chan *dispatchJob) error {
+	state := new(retryState)
+	for ctx.Err() == nil {
+		var err error
+		d.PollingId, err = d.sibylClient.StartPollingWithContext(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			if d.onStartFailed != nil {
+				d.onStartFailed(err)
+			}
+
+			if err = d.wait(ctx, state, err); err != nil {
+				return d.giveUp(err)
+			}
+			continue
+		}
+
+		// the retry state is only reset by a successful GetUpdates, so
+		// connections which are lost right away still back off.
+		if d.onConnected != nil {
+			d.onConnected(d.PollingId)
+		}
+
+		err = d.poll(ctx, queues, state)
+		if err != nil {
+			return d.giveUp(err)
+		}
+	}
+
+	return nil
+}
+
+// poll gets the updates using the current polling id, until the
+// connection is lost or the context is done.
+func (d *SibylDispatcher) poll(ctx context.Context, queues []chan *dispatchJob, state *retryState) error {
+	for ctx.Err() == nil {
+		container, err := d.sibylClient.GetUpdatesWithContext(ctx, d.TimeoutSeconds, d.PollingId)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			if IsNetworkError(err) {
+				// connection issue, try to reconnect.
+				if d.onDisconnected != nil {
+					d.onDisconnected(err)
+				}
+				return d.wait(ctx, state, err)
+			}
+
+			if d.onGetUpdateFailed != nil {
+				d.onGetUpdateFailed(err)
+			}
+
+			if err = d.wait(ctx, state, err); err != nil {
+				return err
+			}
+			continue
+		}
+
+		state.reset()
+
+		// no updates, our request got timed out
+		if container == nil {
+			continue
+		}
+
+		job, err := d.decodeUpdate(container)
+		if err != nil {
+			if d.onGetUpdateFailed != nil {
+				d.onGetUpdateFailed(err)
+			}
+			continue
+		}
+
+		if job != nil && !d.enqueue(ctx, queues, job) {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// wait waits for the delay of the next attempt after a failure; it
+// returns a *GiveUpError if no more attempts should be made. it returns
+// nil if the context is done while waiting.
+func (d *SibylDispatcher) wait(ctx context.Context, state *retryState, err error) error {
+	if state.attempts == 0 {
+		state.since = time.Now()
+	}
+	state.attempts++
+
+	elapsed := time.Since(state.since)
+	giveUpErr := &GiveUpError{
+		Attempts: state.attempts,
+		Elapsed:  elapsed,
+		Err:      err,
+	}
+
+	if d.MaxConnectionTries > 0 && state.attempts >= d.MaxConnectionTries {
+		return giveUpErr
+	}
+
+	backoff := d.Backoff
+	if backoff == nil {
+		backoff = GetDefaultBackoff()
+	}
+
+	delay, ok := backoff.NextDelay(state.attempts, elapsed)
+	if !ok {
+		return giveUpErr
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+
+	return nil
+}
+
+func (d *SibylDispatcher) giveUp(err error) error {
+	if d.onGiveUp != nil {
+		d.onGiveUp(err)
+	}
+
+	return err
+}
+
+func (d *SibylDispatcher) SetOnStartFailed(fn func(error)) {
+	d.onStartFailed = fn
+}
+
+func (d *SibylDispatcher) SetOnGetUpdateFailed(fn func(error)) {
+	d.onGetUpdateFailed = fn
+}
+
+func (d *SibylDispatcher) SetOnHandlerError(fn func(error)) {
+	d.onHandlerError = fn
+}
+
+// SetOnConnected sets a callback which is called each time polling is
+// started successfully, including the reconnections.
+func (d *SibylDispatcher) SetOnConnected(fn func(*PollingIdentifier)) {
+	d.onConnected = fn
+}
+
+// SetOnDisconnected sets a callback which is called when the connection
+// to the server is lost while getting the updates.
+func (d *SibylDispatcher) SetOnDisconnected(fn func(error)) {
+	d.onDisconnected = fn
+}
+
+// SetOnGiveUp sets a callback which is called with a *GiveUpError when
+// the dispatcher gives up connecting to the server; the dispatcher stops
+// after that.
+func (d *SibylDispatcher) SetOnGiveUp(fn func(error)) {
+	d.onGiveUp = fn
+}
+
+// OnScanRequestApproved adds a handler for the approved scan requests.
+func (d *SibylDispatcher) OnScanRequestApproved(fn func(client SibylClient, update *ScanRequestApprovedUpdate) error) {
+	AddUpdateHandler(d, UpdateTypeScanRequestApproved, fn)
+}
+
+// OnScanRequestRejected adds a handler for the rejected scan requests.
+func (d *SibylDispatcher) OnScanRequestRejected(fn func(client SibylClient, update *ScanRequestRejectedUpdate) error) {
+	AddUpdateHandler(d, UpdateTypeScanRequestRejected, fn)
+}
+
+// AddHandler adds a handler for the updates of the given type; the
+// handler gets the raw data of the update as well, so it can be used for
+// the types which have no decoder.
+func (d *SibylDispatcher) AddHandler(uType SibylUpdateType, h ServerUpdateHandler) {
+	handlers := d.handlers.GetValue(uType)
+	handlers = append(handlers, h)
+	d.handlers.Set(uType, handlers)
+}
+
+// startWorkers starts the workers and returns their queues; all of the
+// workers share a single queue, unless the updates should be ordered by
+// their target, in which case each worker has its own queue.
+func (d *SibylDispatcher) startWorkers() []chan *dispatchJob {
+	workers := d.Workers
+	if workers <= 0 {
+		workers = DefaultDispatcherWorkers
+	}
+
+	queueSize := d.QueueSize
+	if queueSize <= 0 {
+		queueSize = DefaultDispatcherQueueSize
+	}
+
+	var queues []chan *dispatchJob
+	if d.OrderByTarget {
+		queueSize /= workers
+		if queueSize < 1 {
+			queueSize = 1
+		}
+
+		for i := 0; i < workers; i++ {
+			queues = append(queues, make(chan *dispatchJob, queueSize))
+		}
+	} else {
+		queues = append(queues, make(chan *dispatchJob, queueSize))
+	}
+
+	d.workersWg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go d.work(queues[i%len(queues)])
+	}
+
+	return queues
+}
+
+func (d *SibylDispatcher) work(queue chan *dispatchJob) {
+	defer d.workersWg.Done()
+
+	for job := range queue {
+		d.handleUpdate(job)
+	}
+}
+
+// enqueue waits until there is room for the job in its queue; it returns
+// false if the context is done before that.
+func (d *SibylDispatcher) enqueue(ctx context.Context, queues []chan *dispatchJob, job *dispatchJob) bool {
+	queue := queues[0]
+	if len(queues) > 1 {
+		queue = queues[uint64(job.ctx.GetTargetUser())%uint64(len(queues))]
+	}
+
+	select {
+	case queue <- job:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// decodeUpdate decodes the data of the update using the decoder of its
+// type; it returns nil if there is no handler for the update.
+func (d *SibylDispatcher) decodeUpdate(container *ServerUpdateContainer) (*dispatchJob, error) {
+	if len(d.handlers.GetValue(container.UpdateType)) == 0 {
+		return nil, nil
+	}
+
+	ctx := &SibylUpdateContext{
+		UpdateType: container.UpdateType,
+		Data:       container.UpdateData,
+	}
+
+	if decoder := GetUpdateDecoder(container.UpdateType); decoder != nil {
+		value, err := decoder(container.UpdateData)
+		if err != nil {
+			return nil, err
+		}
+		ctx.setValue(value)
+	}
+
+	return &dispatchJob{
+		updateType: container.UpdateType,
+		ctx:        ctx,
+	}, nil
+}
+
+func (d *SibylDispatcher) handleUpdate(job *dispatchJob) {
+	handlers := d.handlers.GetValue(job.updateType)
+	for _, current := range handlers {
+		err := current(d.sibylClient, job.ctx)
+		if err != nil && d.onHandlerError != nil {
+			d.onHandlerError(err)
+		}
+	}
+}
+
+//---------------------------------------------------------
+
+// NextDelay returns the delay before the given attempt.
+func (b *ExponentialBackoff) NextDelay(attempt int, elapsed time.Duration) (time.Duration, bool) {
+	if b.MaxElapsedTime > 0 && elapsed >= b.MaxElapsedTime {
+		return 0, false
+	}
+
+	initialDelay := b.InitialDelay
+	if initialDelay <= 0 {
+		initialDelay = DefaultBackoffInitialDelay
+	}
+
+	maxDelay := b.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = DefaultBackoffMaxDelay
+	}
+
+	multiplier := b.Multiplier
+	if multiplier < 1 {
+		multiplier = DefaultBackoffMultiplier
+	}
+
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	delay := float64(initialDelay) * math.Pow(multiplier, float64(attempt-1))
+	if delay > float64(maxDelay) {
+		delay = float64(maxDelay)
+	}
+
+	if b.Jitter > 0 {
+		delay += delay * math.Min(b.Jitter, 1) * (2*rand.Float64() - 1)
+	}
+
+	return time.Duration(delay), true
+}
+
+//---------------------------------------------------------
+
+// NextDelay returns the delay before the given attempt.
+func (b *ConstantBackoff) NextDelay(attempt int, elapsed time.Duration) (time.Duration, bool) {
+	if b.MaxElapsedTime > 0 && elapsed >= b.MaxElapsedTime {
+		return 0, false
+	}
+
+	return b.Delay, true
+}
+
+//---------------------------------------------------------
+
+func (e *GiveUpError) Error() string {
+	return "gave up connecting to sibyl after " + strconv.Itoa(e.Attempts) +
+		" attempts (" + e.Elapsed.Round(time.Millisecond).String() + "): " + e.Err.Error()
+}
+
+func (e *GiveUpError) Unwrap() error {
+	return e.Err
+}
+
+//---------------------------------------------------------
+
+func (s *retryState) reset() {
+	s.attempts = 0
+	s.since = time.Time{}
+}
+
+//---------------------------------------------------------
+
+// GetTargetUser returns the id of the user the update is about, or zero
+// if the value of the update doesn't implement TargetedUpdate.
+func (c *SibylUpdateContext) GetTargetUser() int64 {
+	if update, ok := c.Value.(TargetedUpdate); ok {
+		return update.GetTargetUser()
+	}
+
+	return 0
+}
+
+func (c *SibylUpdateContext) setValue(value any) {
+	c.Value = value
+	switch update := value.(type) {
+	case *ScanRequestApprovedUpdate:
+		c.ScanRequestApproved = update
+	case *ScanRequestRejectedUpdate:
+		c.ScanRequestRejected = update
+	}
+}
+
+//---------------------------------------------------------
+
+func (u *ScanRequestApprovedUpdate) GetTargetUser() int64 {
+	return u.TargetUser
+}
+
+//---------------------------------------------------------
+
+func (u *ScanRequestRejectedUpdate) GetTargetUser() int64 {
+	return u.TargetUser
+}
+
+//---------------------------------------------------------
+
+func (r *updateDecoderRegistry) register(uType SibylUpdateType, decoder UpdateDecoder) error {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	if r.decoders[uType] != nil {
+		return ErrUpdateTypeExists
+	}
+
+	r.decoders[uType] = decoder
+	return nil
+}
+
+func (r *updateDecoderRegistry) get(uType SibylUpdateType) UpdateDecoder {
+	r.mut.RLock()
+	defer r.mut.RUnlock()
+
+	return r.decoders[uType]
+}
+
+//---------------------------------------------------------
+
+// Valid returns true if the flag is registered.
+func (f BanFlag) Valid() bool {
+	return banFlags.exists(f)
+}
+
+// GetInfo returns the metadata of the flag, or nil if the flag isn't
+// registered.
+func (f BanFlag) GetInfo() *BanFlagInfo {
+	return banFlags.get(f)
+}
+
+// GetDescription returns the human readable description of the flag.
+func (f BanFlag) GetDescription() string {
+	if info := banFlags.get(f); info != nil {
+		return info.Description
+	}
+
+	return string(f)
+}
+
+// GetSeverity returns the severity of the flag; unknown flags have
+// zero severity.
+func (f BanFlag) GetSeverity() BanSeverity {
+	if info := banFlags.get(f); info != nil {
+		return info.Severity
+	}
+
+	return 0
+}
+
+// GetNameIn returns the name of the flag in the given language; the
+// default name is the flag in lowercase.
+func (f BanFlag) GetNameIn(tag language.Tag) string {
+	name := strings.ToLower(string(f))
+	return messages.sprintf(tag, MsgKeyFlagPrefix+name, name)
+}
+
+// GetDescriptionIn returns the description of the flag in the given
+// language; it falls back to the description of the registry.
+func (f BanFlag) GetDescriptionIn(tag language.Tag) string {
+	key := MsgKeyFlagPrefix + strings.ToLower(string(f)) + MsgKeyDescriptionSuffix
+	return messages.sprintf(tag, key, f.GetDescription())
+}
+
+// IsCustom returns true if this is the "CUSTOM" flag.
+func (f BanFlag) IsCustom() bool {
+	return f == BanFlagCustom
+}
+
+func (f BanFlag) String() string {
+	return string(f)
+}
+
+// MarshalJSON marshals the flag as a string; in strict mode, unknown
+// flags can't be marshalled.
+func (f BanFlag) MarshalJSON() ([]byte, error) {
+	if banFlags.isStrict() && !banFlags.exists(f) {
+		return nil, ErrUnknownBanFlag
+	}
+
+	return json.Marshal(string(f))
+}
+
+// UnmarshalJSON unmarshals the flag from a string; in strict mode,
+// unknown flags are rejected.
+func (f *BanFlag) UnmarshalJSON(b []byte) error {
+	var value string
+	err := json.Unmarshal(b, &value)
+	if err != nil {
+		return err
+	}
+
+	flag, err := ParseBanFlag(value)
+	if err != nil && banFlags.isStrict() {
+		return err
+	}
+
+	*f = flag
+	return nil
+}
+
+func (s BanSeverity) String() string {
+	switch s {
+	case SeverityLow:
+		return "low"
+	case SeverityMedium:
+		return "medium"
+	case SeverityHigh:
+		return "high"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
+
+func (r *banFlagRegistry) register(info *BanFlagInfo) error {
+	if info == nil || info.Flag == "" {
+		return ErrUnknownBanFlag
+	}
+
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	flag := normalizeBanFlag(string(info.Flag))
+	if r.flags[flag] != nil {
+		return ErrBanFlagExists
+	}
+
+	tmp := *info
+	tmp.Flag = flag
+	r.flags[flag] = &tmp
+	r.order = append(r.order, flag)
+
+	return nil
+}
+
+func (r *banFlagRegistry) get(flag BanFlag) *BanFlagInfo {
+	r.mut.RLock()
+	defer r.mut.RUnlock()
+
+	info := r.flags[flag]
+	if info == nil {
+		return nil
+	}
+
+	tmp := *info
+	return &tmp
+}
+
+func (r *banFlagRegistry) exists(flag BanFlag) bool {
+	r.mut.RLock()
+	defer r.mut.RUnlock()
+
+	return r.flags[flag] != nil
+}
+
+func (r *banFlagRegistry) getAll() []BanFlag {
+	r.mut.RLock()
+	defer r.mut.RUnlock()
+
+	return append([]BanFlag(nil), r.order...)
+}
+
+func (r *banFlagRegistry) setStrict(strict bool) {
+	var value int32
+	if strict {
+		value = 1
+	}
+
+	atomic.StoreInt32(&r.strict, value)
+}
+
+func (r *banFlagRegistry) isStrict() bool {
+	return atomic.LoadInt32(&r.strict) == 1
+}
+
+//---------------------------------------------------------
+
+func (MarkdownFormatter) GetParseMode() string {
+	return ParseModeMarkdownV2
+}
+
+func (MarkdownFormatter) Escape(text string) string {
+	return mdparser.GetNormal(text).ToString()
+}
+
+func (MarkdownFormatter) Style(style TextStyle, text string) string {
+	tags := markdownStyles[style]
+	return tags[0] + text + tags[1]
+}
+
+func (MarkdownFormatter) Link(text, url string) string {
+	url = strings.NewReplacer(`\`, `\\`, `)`, `\)`).Replace(url)
+	return "[" + text + "](" + url + ")"
+}
+
+//---------------------------------------------------------
+
+func (HTMLFormatter) GetParseMode() string {
+	return ParseModeHTML
+}
+
+func (HTMLFormatter) Escape(text string) string {
+	return html.EscapeString(text)
+}
+
+func (HTMLFormatter) Style(style TextStyle, text string) string {
+	tags := htmlStyles[style]
+	return tags[0] + text + tags[1]
+}
+
+func (HTMLFormatter) Link(text, url string) string {
+	return `<a href="` + html.EscapeString(url) + `">` + text + "</a>"
+}
+
+//---------------------------------------------------------
+
+func (PlainFormatter) GetParseMode() string {
+	return ""
+}
+
+func (PlainFormatter) Escape(text string) string {
+	return text
+}
+
+func (PlainFormatter) Style(style TextStyle, text string) string {
+	return text
+}
+
+func (PlainFormatter) Link(text, url string) string {
+	if text == "" || text == url {
+		return url
+	}
+
+	return text + " (" + url + ")"
+}
+
+//---------------------------------------------------------
+
+func (c *messageCatalog) set(tag language.Tag, key string, msg ...catalog.Message) error {
+	if key == "" {
+		return ErrUnknownMessageKey
+	}
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	err := c.builder.Set(tag, key, msg...)
+	if err != nil {
+		return err
+	}
+
+	keys := c.keys[tag]
+	if keys == nil {
+		keys = make(map[string]bool)
+		c.keys[tag] = keys
+
+		// english always comes first, so the matcher falls back to it.
+		if tag == language.English {
+			c.tags = append([]language.Tag{tag}, c.tags...)
+		} else {
+			c.tags = append(c.tags, tag)
+		}
+		c.matcher = language.NewMatcher(c.tags)
+	}
+
+	keys[key] = true
+	return nil
+}
+
+// sprintf formats the message of the key in the closest registered
+// language to tag; if that language doesn't have the key, the english
+// message is used, and if english doesn't have it either, fallback is
+// returned as it is.
+func (c *messageCatalog) sprintf(tag language.Tag, key, fallback string, args ...interface{}) string {
+	c.mut.RLock()
+	matched := c.match(tag)
+	if !c.keys[matched][key] {
+		matched = language.English
+	}
+	exists := c.keys[matched][key]
+	c.mut.RUnlock()
+
+	if !exists {
+		return fallback
+	}
+
+	return message.NewPrinter(matched, message.Catalog(c.builder)).Sprintf(key, args...)
+}
+
+func (c *messageCatalog) match(tag language.Tag) language.Tag {
+	if c.keys[tag] != nil {
+		return tag
+	}
+
+	_, index, confidence := c.matcher.Match(tag)
+	if confidence == language.No {
+		return language.English
+	}
+
+	return c.tags[index]
+}
+
+func (c *messageCatalog) getTags() []language.Tag {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+
+	return append([]language.Tag(nil), c.tags...)
+}
+
+//---------------------------------------------------------
+
+func (e EntityType) ToString() string {
+	return ws.ToBase10(int64(e))
+}
+
+// IsValid returns true if the entity type is one of the known types.
+func (e EntityType) IsValid() bool {
+	return e >= EntityTypeUser && e <= EntityTypeGroup
+}
+
+// GetName returns the english name of the entity type.
+func (e EntityType) GetName() string {
+	return e.GetNameIn(language.English)
+}
+
+// GetNameIn returns the name of the entity type in the given language.
+func (e EntityType) GetNameIn(tag language.Tag) string {
+	key, ok := entityTypeKeys[e]
+	if !ok {
+		key = MsgKeyUnknown
+	}
+
+	return Localize(tag, key)
+}
+
+// FormatWith formats the name of the entity type in the given language.
+func (e EntityType) FormatWith(f Formatter, tag language.Tag) string {
+	return f.Escape(e.GetNameIn(tag))
+}
+
+func (e EntityType) IsBot() bool {
+	return e == EntityTypeBot
+}
+
+func (e EntityType) IsBotStr() string {
+	return e.IsBotStrIn(language.English)
+}
+
+// IsBotStrIn is the same as IsBotStr, in the given language.
+func (e EntityType) IsBotStrIn(tag language.Tag) string {
+	return YesOrNoIn(tag, e.IsBot())
+}
+
+func (e EntityType) IsUser() bool {
+	return e == EntityTypeUser
+}
+
+func (e EntityType) IsUserStr() string {
+	return e.IsUserStrIn(language.English)
+}
+
+// IsUserStrIn is the same as IsUserStr, in the given language.
+func (e EntityType) IsUserStrIn(tag language.Tag) string {
+	return YesOrNoIn(tag, e.IsUser())
+}
+
+func (e EntityType) IsAdmin() bool {
+	return e == EntityTypeAdmin
+}
+
+func (e EntityType) IsAdminStr() string {
+	return e.IsAdminStrIn(language.English)
+}
+
+// IsAdminStrIn is the same as IsAdminStr, in the given language.
+func (e EntityType) IsAdminStrIn(tag language.Tag) string {
+	return YesOrNoIn(tag, e.IsAdmin())
+}
+
+func (e EntityType) IsOwner() bool {
+	return e == EntityTypeOwner
+}
+
+func (e EntityType) IsOwnerStr() string {
+	return e.IsOwnerStrIn(language.English)
+}
+
+// IsOwnerStrIn is the same as IsOwnerStr, in the given language.
+func (e EntityType) IsOwnerStrIn(tag language.Tag) string {
+	return YesOrNoIn(tag, e.IsOwner())
+}
+
+func (e EntityType) IsGroup() bool {
+	return e == EntityTypeGroup
+}
+
+func (e EntityType) IsGroupStr() string {
+	return e.IsGroupStrIn(language.English)
+}
+
+// IsGroupStrIn is the same as IsGroupStr, in the given language.
+func (e EntityType) IsGroupStrIn(tag language.Tag) string {
+	return YesOrNoIn(tag, e.IsGroup())
+}
+
+func (e EntityType) IsChannel() bool {
+	return e == EntityTypeChannel
+}
+
+func (e EntityType) IsChannelStr() string {
+	return e.IsChannelStrIn(language.English)
+}
+
+// IsChannelStrIn is the same as IsChannelStr, in the given language.
+func (e EntityType) IsChannelStrIn(tag language.Tag) string {
+	return YesOrNoIn(tag, e.IsChannel())
+}
+
+func (e EntityType) IsChat() bool {
+	return e == EntityTypeChannel || e == EntityTypeGroup
+}
 
 func (e EntityType) IsChatStr() string {
-	return ws.YesOrNo(e == EntityTypeChannel || e == EntityTypeGroup)
+	return e.IsChatStrIn(language.English)
+}
+
+// IsChatStrIn is the same as IsChatStr, in the given language.
+func (e EntityType) IsChatStrIn(tag language.Tag) string {
+	return YesOrNoIn(tag, e.IsChat())
+}
+
+func (e EntityType) IsOwnerOrAdmin() bool {
+	return e == EntityTypeOwner || e == EntityTypeAdmin
+}
+
+func (e EntityType) IsOwnerOrAdminStr() string {
+	return e.IsOwnerOrAdminStrIn(language.English)
+}
+
+// IsOwnerOrAdminStrIn is the same as IsOwnerOrAdminStr, in the given language.
+func (e EntityType) IsOwnerOrAdminStrIn(tag language.Tag) string {
+	return YesOrNoIn(tag, e.IsOwnerOrAdmin())
+}
+
+//---------------------------------------------------------
+
+// UnmarshalJSON parses the time from either a string (using all of the
+// layouts in SibylTimeLayouts) or a unix timestamp. values which can't be
+// parsed are kept as they are, with a zero time.
+func (t *SibylTime) UnmarshalJSON(b []byte) error {
+	t.raw = append([]byte(nil), b...)
+	t.Time = time.Time{}
+	defer func() {
+		t.parsed = t.Time
+	}()
+
+	value := string(b)
+	if value == "null" {
+		return nil
+	}
+
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		parsed, _ := ParseSibylTime(unquoted)
+		t.Time = parsed.Time
+		return nil
+	}
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return err
+	}
+
+	// timestamps bigger than this are in milliseconds.
+	if n > 1e12 {
+		t.Time = time.UnixMilli(int64(n))
+	} else {
+		t.Time = time.Unix(int64(n), 0)
+	}
+
+	return nil
+}
+
+// UnmarshalText is the same as UnmarshalJSON, for the text encodings
+// (e.g. yaml); the value is either a unix timestamp, or a time string.
+func (t *SibylTime) UnmarshalText(b []byte) error {
+	if _, err := strconv.ParseFloat(string(b), 64); err == nil {
+		return t.UnmarshalJSON(b)
+	}
+
+	return t.UnmarshalJSON([]byte(strconv.Quote(string(b))))
+}
+
+// MarshalJSON returns the original value if the time has been received
+// from the server (and hasn't been changed since), otherwise the time is
+// marshalled in RFC3339 format.
+func (t SibylTime) MarshalJSON() ([]byte, error) {
+	if raw := t.getRaw(); raw != nil {
+		return raw, nil
+	}
+
+	if t.IsZero() {
+		return []byte(`""`), nil
+	}
+
+	return []byte(strconv.Quote(t.Time.Format(time.RFC3339Nano))), nil
+}
+
+// MarshalText is the same as MarshalJSON, for the text encodings (e.g.
+// yaml); the value is the same as String.
+func (t SibylTime) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// String returns the original value of the time (without quotes) if it's
+// been received from the server (and hasn't been changed since), otherwise
+// it's formatted using RFC3339.
+func (t SibylTime) String() string {
+	if raw := t.getRaw(); raw != nil {
+		if unquoted, err := strconv.Unquote(string(raw)); err == nil {
+			return unquoted
+		}
+
+		if string(raw) == "null" {
+			return ""
+		}
+		return string(raw)
+	}
+
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Time.Format(time.RFC3339)
+}
+
+// GetRaw returns the original value received from the server.
+func (t SibylTime) GetRaw() string {
+	return string(t.raw)
+}
+
+// IsSame returns true if both of the times have the same value; the
+// original values are compared if both of them have been received from
+// the server.
+func (t SibylTime) IsSame(other SibylTime) bool {
+	raw, otherRaw := t.getRaw(), other.getRaw()
+	if raw != nil && otherRaw != nil {
+		return string(raw) == string(otherRaw)
+	}
+
+	return t.Time.Equal(other.Time)
 }
 
-func (e EntityType) IsOwnerOrAdmin() bool {
-	return e == EntityTypeOwner || e == EntityTypeAdmin
+// getRaw returns the original value, or nil if there is none or the time
+// has been changed after parsing it.
+func (t SibylTime) getRaw() []byte {
+	if t.raw == nil || !t.Time.Equal(t.parsed) {
+		return nil
+	}
+
+	return t.raw
 }
 
-func (e EntityType) IsOwnerOrAdminStr() string {
-	return ws.YesOrNo(e == EntityTypeOwner || e == EntityTypeAdmin)
+// FormatIn formats the time in the given location using the given layout;
+// if layout is empty, DefaultTimeLayout is used. unparsed values are
+// returned as they are.
+func (t SibylTime) FormatIn(loc *time.Location, layout string) string {
+	if t.IsZero() {
+		return t.String()
+	}
+
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	if layout == "" {
+		layout = DefaultTimeLayout
+	}
+
+	return t.Time.In(loc).Format(layout)
+}
+
+// FormatInZone is the same as FormatIn, but it takes the name of the time
+// zone (e.g. "Asia/Tehran") instead of the location itself.
+func (t SibylTime) FormatInZone(zone, layout string) (string, error) {
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return "", err
+	}
+
+	return t.FormatIn(loc, layout), nil
+}
+
+// FormatShort returns the date of the time in ShortDateLayout.
+func (t SibylTime) FormatShort() string {
+	if t.IsZero() {
+		return t.String()
+	}
+
+	return t.Time.Format(ShortDateLayout)
+}
+
+// FormatRelative returns the time relative to now in a human readable
+// form, e.g. "3 days ago" or "in 2 hours".
+func (t SibylTime) FormatRelative(now time.Time) string {
+	return t.FormatRelativeIn(language.English, now)
+}
+
+// FormatRelativeIn is the same as FormatRelative, in the given language.
+func (t SibylTime) FormatRelativeIn(tag language.Tag, now time.Time) string {
+	if t.IsZero() {
+		return t.String()
+	}
+
+	d := now.Sub(t.Time)
+	future := d < 0
+	if future {
+		d = -d
+	}
+
+	if d < 10*time.Second {
+		return Localize(tag, MsgKeyJustNow)
+	}
+
+	count, unit := getRelativeUnit(d)
+	result := Localize(tag, unit, count)
+	if future {
+		return Localize(tag, MsgKeyTimeIn, result)
+	}
+
+	return Localize(tag, MsgKeyTimeAgo, result)
+}
+
+// Ago is the same as FormatRelative, relative to the current time.
+func (t SibylTime) Ago() string {
+	return t.FormatRelative(time.Now())
+}
+
+// AgoIn is the same as Ago, in the given language.
+func (t SibylTime) AgoIn(tag language.Tag) string {
+	return t.FormatRelativeIn(tag, time.Now())
 }
 
 //---------------------------------------------------------
@@ -1008,3 +2507,553 @@ func (p *PollingIdentifier) IsInvalid() bool {
 }
 
 //---------------------------------------------------------
+
+// getUserId returns the user-id of the target, or false if the target
+// is nil.
+func (t *BanTarget) getUserId() (int64, bool) {
+	if t == nil {
+		return 0, false
+	}
+
+	return t.UserId, true
+}
+
+// GetReason returns the reason of the target, with its flags appended
+// to it as hashtags (if they don't exist in the reason already).
+func (t *BanTarget) GetReason() string {
+	reason := t.Reason
+	parsed := ParseReason(reason)
+	added := make(map[BanFlag]bool, len(t.Flags))
+	for _, current := range t.Flags {
+		flag := normalizeBanFlag(string(current))
+		if flag == "" || added[flag] || parsed.HasFlag(flag) {
+			continue
+		}
+		added[flag] = true
+
+		if reason != "" {
+			reason += " "
+		}
+		reason += "#" + string(flag)
+	}
+
+	return reason
+}
+
+//---------------------------------------------------------
+
+// AddFlags adds the given flags to the reason; duplicated flags
+// are ignored.
+func (b *ReasonBuilder) AddFlags(flags ...BanFlag) *ReasonBuilder {
+	for _, current := range flags {
+		current = normalizeBanFlag(string(current))
+		if current == "" || b.HasFlag(current) {
+			continue
+		}
+
+		b.flags = append(b.flags, current)
+	}
+
+	return b
+}
+
+// AddText adds free text to the reason.
+func (b *ReasonBuilder) AddText(text string) *ReasonBuilder {
+	text = strings.TrimSpace(text)
+	if text != "" {
+		b.texts = append(b.texts, text)
+	}
+
+	return b
+}
+
+// SetPerma marks the ban as permanent.
+func (b *ReasonBuilder) SetPerma(perma bool) *ReasonBuilder {
+	b.perma = perma
+	return b
+}
+
+// HasFlag returns true if the flag is already added to the builder.
+func (b *ReasonBuilder) HasFlag(flag BanFlag) bool {
+	for _, current := range b.flags {
+		if current == flag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetFlags returns the flags added to the builder.
+func (b *ReasonBuilder) GetFlags() []BanFlag {
+	return append([]BanFlag(nil), b.flags...)
+}
+
+// Build validates the flags and returns the reason; flags come first as
+// hashtags, then the text and then the perma marker.
+func (b *ReasonBuilder) Build() (string, error) {
+	for _, current := range b.flags {
+		if !current.Valid() {
+			return "", ErrUnknownBanFlag
+		}
+	}
+
+	reason := b.String()
+	if err := ValidateReason(reason); err != nil {
+		return "", err
+	}
+
+	return reason, nil
+}
+
+// String returns the reason without validating it.
+func (b *ReasonBuilder) String() string {
+	parts := make([]string, 0, len(b.flags)+len(b.texts)+1)
+	for _, current := range b.flags {
+		parts = append(parts, "#"+string(current))
+	}
+
+	parts = append(parts, b.texts...)
+	if b.perma && !ParseReason(strings.Join(b.texts, " ")).Perma {
+		parts = append(parts, PermaMarker)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+//---------------------------------------------------------
+
+// HasFlag returns true if the reason contains the given flag.
+func (p *ParsedReason) HasFlag(flag BanFlag) bool {
+	for _, current := range p.Flags {
+		if current == flag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsCustom returns true if the reason has no known flags, in which
+// case sibyl considers it as a custom ban.
+func (p *ParsedReason) IsCustom() bool {
+	return len(p.Flags) == 0 || p.HasFlag(BanFlagCustom)
+}
+
+//---------------------------------------------------------
+
+// getUserId returns the user-id of the target, or false if the target
+// is nil.
+func (t *RevertTarget) getUserId() (int64, bool) {
+	if t == nil {
+		return 0, false
+	}
+
+	return t.UserId, true
+}
+
+//---------------------------------------------------------
+
+func (o *BulkOptions) getConcurrency() int {
+	if o.Concurrency <= 0 {
+		return DefaultBulkConcurrency
+	}
+
+	return o.Concurrency
+}
+
+// add adds the outcome to the counters of the report; the outcome
+// itself should already be placed in Outcomes.
+func (r *BanManyReport) add(outcome *BanOutcome) {
+	switch {
+	case outcome.Skipped:
+		r.SkippedCount++
+	case outcome.Err != nil:
+		r.FailedCount++
+	default:
+		r.SucceededCount++
+	}
+}
+
+// GetFailed returns the outcomes of the failed targets.
+func (r *BanManyReport) GetFailed() []*BanOutcome {
+	var failed []*BanOutcome
+	for _, current := range r.Outcomes {
+		if current != nil && current.Err != nil {
+			failed = append(failed, current)
+		}
+	}
+
+	return failed
+}
+
+func (r *RemoveBanManyReport) add(outcome *RevertOutcome) {
+	switch {
+	case outcome.Skipped:
+		r.SkippedCount++
+	case outcome.Err != nil:
+		r.FailedCount++
+	default:
+		r.SucceededCount++
+	}
+}
+
+// GetFailed returns the outcomes of the failed targets.
+func (r *RemoveBanManyReport) GetFailed() []*RevertOutcome {
+	var failed []*RevertOutcome
+	for _, current := range r.Outcomes {
+		if current != nil && current.Err != nil {
+			failed = append(failed, current)
+		}
+	}
+
+	return failed
+}
+
+// IsSuccessful returns true if the target has been banned, either in this
+// run or in a previous run which has been resumed.
+func (o *BanOutcome) IsSuccessful() bool {
+	return o != nil && o.Err == nil
+}
+
+// WasAlreadyBanned returns true if the user had another ban before
+// this one.
+func (o *BanOutcome) WasAlreadyBanned() bool {
+	return o != nil && o.Result != nil && o.Result.PreviousBan != nil &&
+		o.Result.PreviousBan.Banned
+}
+
+// IsSuccessful returns true if the ban of the target has been removed,
+// either in this run or in a previous run which has been resumed.
+func (o *RevertOutcome) IsSuccessful() bool {
+	return o != nil && o.Err == nil
+}
+
+func (c *bulkCheckpoint[T]) get(userId int64) (T, bool) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	value, ok := c.Completed[userId]
+	return value, ok
+}
+
+// add marks the user as completed and saves the checkpoint (if it has
+// a path).
+func (c *bulkCheckpoint[T]) add(userId int64, value T) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.Completed[userId] = value
+	if c.path == "" {
+		return nil
+	}
+
+	return SaveJSONFile(c.path, c)
+}
+
+//---------------------------------------------------------
+
+// get returns the cached info of the user, or nil if it's not cached
+// or has been expired.
+func (c *infoCache) get(userId int64) *GetInfoResult {
+	value := c.getValue(userId)
+	if value == nil {
+		return nil
+	}
+
+	return value.info
+}
+
+func (c *infoCache) getValue(userId int64) *cachedInfo {
+	if c.ttl <= 0 {
+		return nil
+	}
+
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+
+	value := c.values[userId]
+	if value == nil || time.Since(value.cachedAt) > c.ttl {
+		return nil
+	}
+
+	return value
+}
+
+// set caches the info of the user, unless the user has been invalidated
+// after the info was requested.
+func (c *infoCache) set(userId int64, info *GetInfoResult, requestedAt time.Time) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if invalidatedAt, ok := c.invalidated[userId]; ok && !requestedAt.After(invalidatedAt) {
+		return
+	}
+
+	now := time.Now()
+	c.values[userId] = &cachedInfo{
+		info:     info,
+		cachedAt: now,
+	}
+
+	// expired values are swept at most once per ttl, to prevent the cache
+	// from growing forever.
+	if now.Sub(c.lastSweep) > c.ttl {
+		c.lastSweep = now
+		for key, value := range c.values {
+			if now.Sub(value.cachedAt) > c.ttl {
+				delete(c.values, key)
+			}
+		}
+
+		for key, value := range c.invalidated {
+			if now.Sub(value) > c.ttl {
+				delete(c.invalidated, key)
+			}
+		}
+	}
+}
+
+// delete removes the cached info of the user, e.g. after the user has
+// been banned; the in-flight requests for the user won't be cached.
+func (c *infoCache) delete(userId int64) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mut.Lock()
+	delete(c.values, userId)
+	c.invalidated[userId] = time.Now()
+	c.mut.Unlock()
+}
+
+func (c *infoCache) clear() {
+	c.mut.Lock()
+	c.values = make(map[int64]*cachedInfo)
+	c.mut.Unlock()
+}
+
+//---------------------------------------------------------
+
+// do executes fn for the given key, unless there is already a call
+// in-flight for the same key; in that case it waits for that call and
+// returns its result instead. cancelling ctx only stops the current caller
+// from waiting; the shared call itself gets cancelled only if all of its
+// callers have given up, or if the parent context is done.
+func (g *flightGroup[TKey, TValue]) do(
+	ctx, parent context.Context,
+	key TKey,
+	fn func(context.Context) (TValue, error),
+) (TValue, error) {
+	if ctx == nil {
+		ctx = parent
+	}
+
+	g.mut.Lock()
+	call := g.calls[key]
+	if call == nil {
+		callCtx, cancel := context.WithCancel(parent)
+		call = &flightCall[TValue]{
+			done:   make(chan struct{}),
+			cancel: cancel,
+		}
+		g.calls[key] = call
+
+		go g.execute(callCtx, key, call, fn)
+	}
+	call.waiters++
+	g.mut.Unlock()
+
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		g.leave(key, call)
+
+		var zero TValue
+		return zero, ctx.Err()
+	}
+}
+
+func (g *flightGroup[TKey, TValue]) execute(
+	ctx context.Context,
+	key TKey,
+	call *flightCall[TValue],
+	fn func(context.Context) (TValue, error),
+) {
+	defer call.cancel()
+
+	value, err := fn(ctx)
+
+	g.mut.Lock()
+	call.value, call.err = value, err
+	g.forget(key, call)
+	g.mut.Unlock()
+
+	close(call.done)
+}
+
+// leave removes a waiter from the call; if it was the last one, the call
+// gets cancelled and forgotten, so new callers won't join a dying request.
+func (g *flightGroup[TKey, TValue]) leave(key TKey, call *flightCall[TValue]) {
+	g.mut.Lock()
+	defer g.mut.Unlock()
+
+	call.waiters--
+	if call.waiters > 0 {
+		return
+	}
+
+	g.forget(key, call)
+	call.cancel()
+}
+
+func (g *flightGroup[TKey, TValue]) forget(key TKey, call *flightCall[TValue]) {
+	if g.calls[key] == call {
+		delete(g.calls, key)
+	}
+}
+
+//---------------------------------------------------------
+
+// IsBanned decides whether the user is banned; it never returns nil. the
+// cache of the client is checked first, then the user is looked up live;
+// if the lookup fails, the verdict is decided by the degradation policy
+// and the error is set in Verdict.Err. the bans and reverts made by the
+// client drop the cached info of the user, so they are seen right away;
+// the changes made by others are seen after the info cache expires.
+func (c *BanChecker) IsBanned(ctx context.Context, userId int64) *Verdict {
+	verdict := &Verdict{
+		UserId: userId,
+	}
+
+	if info, cachedAt := c.client.GetCachedInfo(userId); info != nil {
+		verdict.setInfo(info, VerdictSourceCache, cachedAt)
+		return verdict
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	info, err := c.client.GetInfoWithContext(ctx, userId)
+	if err == nil && info != nil {
+		if c.lastKnown != nil {
+			c.lastKnown.set(userId, info.clone(), time.Now())
+		}
+		verdict.setInfo(info, VerdictSourceLive, time.Now())
+		return verdict
+	}
+
+	if err == nil {
+		err = ErrInvalidResp
+	}
+
+	verdict.Err = err
+	switch c.policy {
+	case DegradeFailClosed:
+		verdict.Banned = true
+	case DegradeUseStale:
+		if !c.useStale(verdict) {
+			verdict.Banned = c.staleFallback == DegradeFailClosed
+		}
+	}
+
+	return verdict
+}
+
+// GetPolicy returns the degradation policy of the checker.
+func (c *BanChecker) GetPolicy() DegradationPolicy {
+	return c.policy
+}
+
+// GetMaxStaleness returns the max age of the data used with
+// DegradeUseStale.
+func (c *BanChecker) GetMaxStaleness() time.Duration {
+	return c.maxStaleness
+}
+
+// useStale sets the verdict from the last known info of the user, or
+// from the mirror; it returns false if neither of them is fresh enough.
+func (c *BanChecker) useStale(verdict *Verdict) bool {
+	if value := c.lastKnown.getValue(verdict.UserId); value != nil {
+		verdict.setInfo(value.info.clone(), VerdictSourceCache, value.cachedAt)
+		return true
+	}
+
+	if c.mirror == nil {
+		return false
+	}
+
+	syncedAt := c.mirror.GetSyncedAt()
+	if syncedAt.IsZero() || time.Since(syncedAt) > c.maxStaleness {
+		return false
+	}
+
+	verdict.Source = VerdictSourceMirror
+	verdict.FetchedAt = syncedAt
+	verdict.Ban = c.mirror.GetBan(verdict.UserId)
+	verdict.Banned = verdict.Ban != nil
+	return true
+}
+
+//---------------------------------------------------------
+
+// IsDegraded returns true if the verdict hasn't been decided by a live
+// or a fresh cached lookup.
+func (v *Verdict) IsDegraded() bool {
+	return v.Err != nil
+}
+
+// GetAge returns the age of the data of the verdict; it's zero for
+// VerdictSourceDefault.
+func (v *Verdict) GetAge() time.Duration {
+	if v.FetchedAt.IsZero() {
+		return 0
+	}
+
+	return time.Since(v.FetchedAt)
+}
+
+func (v *Verdict) setInfo(info *GetInfoResult, source VerdictSource, fetchedAt time.Time) {
+	v.Info = info
+	v.Banned = info.Banned
+	v.Source = source
+	v.FetchedAt = fetchedAt
+}
+
+//---------------------------------------------------------
+
+func (s VerdictSource) String() string {
+	switch s {
+	case VerdictSourceDefault:
+		return "default"
+	case VerdictSourceLive:
+		return "live"
+	case VerdictSourceCache:
+		return "cache"
+	case VerdictSourceMirror:
+		return "mirror"
+	default:
+		return "unknown"
+	}
+}
+
+//---------------------------------------------------------
+
+func (p DegradationPolicy) String() string {
+	switch p {
+	case DegradeFailOpen:
+		return "fail-open"
+	case DegradeFailClosed:
+		return "fail-closed"
+	case DegradeUseStale:
+		return "use-stale"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,28 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package sibylBot
+
+// commands handled by the adapter.
+const (
+	CommandScan   = "scan"
+	CommandReport = "report"
+	CommandInfo   = "sibylinfo"
+)
+
+const (
+	// DefaultBotApiUrl is the url of the official telegram bot api server.
+	DefaultBotApiUrl = "https://api.telegram.org"
+)
+
+// keys of the messages sent by the command handlers.
+const (
+	MsgKeyScanUsage     = "bot.scan.usage"
+	MsgKeyReportUsage   = "bot.report.usage"
+	MsgKeyReportSent    = "bot.report.sent"
+	MsgKeyInfoUsage     = "bot.info.usage"
+	MsgKeyCommandFailed = "bot.command.failed"
+	MsgKeyNotAllowed    = "bot.command.not-allowed"
+)
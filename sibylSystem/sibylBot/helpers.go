@@ -0,0 +1,148 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package sibylBot
+
+import (
+	"net/http"
+	urlLib "net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/sibylGuard"
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/sibylRender"
+	"golang.org/x/text/language"
+)
+
+func init() {
+	_ = sibylSystem.RegisterLocale(language.English, englishMessages)
+}
+
+// NewAdapter returns a new adapter which checks the users using the
+// client and executes the decisions using the bot.
+func NewAdapter(client sibylSystem.SibylClient, bot BotApi, config *AdapterConfig) (*Adapter, error) {
+	if client == nil {
+		return nil, ErrNoClient
+	}
+
+	if bot == nil {
+		return nil, ErrNoBotApi
+	}
+
+	if config == nil {
+		config = &AdapterConfig{}
+	}
+
+	a := &Adapter{
+		client:    client,
+		bot:       bot,
+		guard:     config.Guard,
+		renderer:  config.Renderer,
+		quiet:     config.Quiet,
+		enforcers: make(map[int64]bool, len(config.Enforcers)),
+		commands:  make(map[string]CommandHandler),
+	}
+
+	var err error
+	if a.renderer == nil {
+		a.renderer, err = sibylRender.NewRenderer(sibylRender.FormatMarkdownV2, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if a.guard == nil {
+		a.guard, err = sibylGuard.NewGuard(client, &sibylGuard.GuardConfig{
+			Renderer: a.renderer,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, current := range config.Enforcers {
+		a.enforcers[current] = true
+	}
+
+	a.commands[CommandScan] = a.handleScan
+	a.commands[CommandReport] = a.handleReport
+	a.commands[CommandInfo] = a.handleInfo
+
+	return a, nil
+}
+
+// NewHttpBotApi returns a BotApi which sends the requests to the bot
+// api server directly.
+func NewHttpBotApi(token string, config *HttpBotApiConfig) *HttpBotApi {
+	if config == nil {
+		config = &HttpBotApiConfig{}
+	}
+
+	api := &HttpBotApi{
+		token:      token,
+		apiUrl:     strings.TrimSuffix(config.ApiUrl, "/"),
+		httpClient: config.HttpClient,
+	}
+
+	if api.apiUrl == "" {
+		api.apiUrl = DefaultBotApiUrl
+	}
+
+	if api.httpClient == nil {
+		api.httpClient = http.DefaultClient
+	}
+
+	return api
+}
+
+func getMemberValues(chatId, userId int64) urlLib.Values {
+	v := urlLib.Values{}
+	v.Set("chat_id", strconv.FormatInt(chatId, 10))
+	v.Set("user_id", strconv.FormatInt(userId, 10))
+	return v
+}
+
+// getTargetUser returns the user which the command is about: the id in
+// the args, or the sender of the replied message. it returns zero if
+// there is no target, or the id is invalid.
+func getTargetUser(msg *Message, args string) (int64, bool) {
+	if args != "" {
+		id, err := strconv.ParseInt(strings.Fields(args)[0], 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return id, false
+	}
+
+	if msg.ReplyTo != nil && msg.ReplyTo.SenderId != 0 {
+		return msg.ReplyTo.SenderId, msg.ReplyTo.SenderIsBot
+	}
+
+	return 0, false
+}
+
+// ParseCommand parses a command from the text of a message, e.g.
+// "/scan@mybot spam" is parsed to ("scan", "spam").
+func ParseCommand(text string) (name, args string, ok bool) {
+	if !strings.HasPrefix(text, "/") {
+		return "", "", false
+	}
+
+	name = text[1:]
+	if index := strings.IndexAny(name, " \n\t"); index != -1 {
+		name, args = name[:index], strings.TrimSpace(name[index+1:])
+	}
+
+	if index := strings.IndexByte(name, '@'); index != -1 {
+		name = name[:index]
+	}
+
+	if name == "" {
+		return "", "", false
+	}
+
+	return strings.ToLower(name), args, true
+}
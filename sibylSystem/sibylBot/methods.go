@@ -0,0 +1,317 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package sibylBot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	urlLib "net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/sibylGuard"
+)
+
+// HandleMessage checks the new members or the sender of the message,
+// executes the decisions of the guard, and handles the commands of the
+// message if its sender is allowed to stay in the chat.
+func (a *Adapter) HandleMessage(ctx context.Context, msg *Message) error {
+	_, err := a.ProcessMessage(ctx, msg)
+	return err
+}
+
+// ProcessMessage works like HandleMessage, and also returns false if the
+// sender of the message or any of the new members isn't allowed in the
+// chat, so the message shouldn't be handled any further by the bot.
+func (a *Adapter) ProcessMessage(ctx context.Context, msg *Message) (bool, error) {
+	if msg == nil {
+		return true, nil
+	}
+
+	if len(msg.NewMembers) != 0 {
+		allowed := true
+		var firstErr error
+		for _, current := range msg.NewMembers {
+			decision := a.guard.CheckJoin(ctx, msg.ChatId, current.UserId, current.IsBot)
+			err := a.Enforce(ctx, decision, msg.MessageId)
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			allowed = allowed && decision.IsAllowed()
+		}
+
+		return allowed, firstErr
+	}
+
+	if msg.SenderId != 0 {
+		decision := a.guard.CheckMessage(ctx, msg.ChatId, msg.SenderId, msg.SenderIsBot)
+		err := a.Enforce(ctx, decision, msg.MessageId)
+		if err != nil || !decision.IsAllowed() {
+			return decision.IsAllowed(), err
+		}
+	}
+
+	_, err := a.HandleCommand(ctx, msg)
+	return true, err
+}
+
+// Enforce executes the decision of the guard in its chat, and announces
+// it unless the adapter is quiet.
+func (a *Adapter) Enforce(ctx context.Context, decision *sibylGuard.Decision, replyTo int64) error {
+	err := a.execute(ctx, decision)
+	if err != nil || a.quiet || decision == nil || decision.IsAllowed() || decision.Reason == "" {
+		return err
+	}
+
+	return a.bot.SendMessage(ctx, decision.Event.ChatId, decision.Reason, a.getParseMode(), replyTo)
+}
+
+// execute executes the decision of the guard in its chat, without
+// announcing it.
+func (a *Adapter) execute(ctx context.Context, decision *sibylGuard.Decision) error {
+	if decision == nil || decision.Event == nil || decision.IsAllowed() {
+		return nil
+	}
+
+	chatId, userId := decision.Event.ChatId, decision.Event.UserId
+
+	var err error
+	switch decision.Action {
+	case sibylGuard.ActionBan:
+		err = a.bot.BanChatMember(ctx, chatId, userId)
+	case sibylGuard.ActionKick:
+		err = a.bot.BanChatMember(ctx, chatId, userId)
+		if err == nil {
+			err = a.bot.UnbanChatMember(ctx, chatId, userId)
+		}
+	case sibylGuard.ActionMute:
+		err = a.bot.RestrictChatMember(ctx, chatId, userId, false)
+	}
+
+	return err
+}
+
+// HandleCommand handles the command of the message, if it has any; it
+// returns true if the message contained a known command.
+func (a *Adapter) HandleCommand(ctx context.Context, msg *Message) (bool, error) {
+	name, args, ok := ParseCommand(msg.Text)
+	if !ok {
+		return false, nil
+	}
+
+	handler := a.commands[name]
+	if handler == nil {
+		return false, nil
+	}
+
+	text, err := handler(ctx, msg, args)
+	if err != nil {
+		text = a.localize(MsgKeyCommandFailed)
+	}
+
+	if text != "" {
+		sendErr := a.bot.SendMessage(ctx, msg.ChatId, text, a.getParseMode(), msg.MessageId)
+		if err == nil {
+			err = sendErr
+		}
+	}
+
+	return true, err
+}
+
+// AddCommand adds a command handler, or replaces one of the built-in
+// handlers.
+func (a *Adapter) AddCommand(name string, handler CommandHandler) {
+	a.commands[strings.ToLower(name)] = handler
+}
+
+// HasCommand returns true if the adapter has a handler for the command.
+func (a *Adapter) HasCommand(name string) bool {
+	return a.commands[strings.ToLower(name)] != nil
+}
+
+// GetCommands returns the names of the commands of the adapter, sorted.
+func (a *Adapter) GetCommands() []string {
+	names := make([]string, 0, len(a.commands))
+	for name := range a.commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// IsEnforcer returns true if the user is allowed to scan and report users.
+func (a *Adapter) IsEnforcer(userId int64) bool {
+	return a.enforcers[userId]
+}
+
+// handleScan checks the user with the guard and executes its decision in
+// the chat; the decision (or the info of the user, if they're allowed) is
+// sent as the reply.
+func (a *Adapter) handleScan(ctx context.Context, msg *Message, args string) (string, error) {
+	if !a.IsEnforcer(msg.SenderId) {
+		return a.localize(MsgKeyNotAllowed), nil
+	}
+
+	userId, isBot := getTargetUser(msg, args)
+	if userId == 0 {
+		return a.localize(MsgKeyScanUsage), nil
+	}
+
+	// unlike the automatic checks, a failed scan never fails closed: the
+	// enforcer gets the error and can scan the user again.
+	decision := a.guard.CheckMessage(ctx, msg.ChatId, userId, isBot)
+	if decision.Err != nil {
+		return "", decision.Err
+	}
+
+	if decision.IsAllowed() {
+		if decision.Info == nil {
+			return "", sibylSystem.ErrInvalidResp
+		}
+		return a.renderer.RenderInfo(decision.Info)
+	}
+
+	return decision.Reason, a.execute(ctx, decision)
+}
+
+func (a *Adapter) handleReport(ctx context.Context, msg *Message, args string) (string, error) {
+	if !a.IsEnforcer(msg.SenderId) {
+		return a.localize(MsgKeyNotAllowed), nil
+	}
+
+	target := msg.ReplyTo
+	if target == nil || target.SenderId == 0 || args == "" {
+		return a.localize(MsgKeyReportUsage), nil
+	}
+
+	config := &sibylSystem.ReportConfig{
+		Message:    target.Text,
+		TargetType: sibylSystem.EntityTypeUser,
+	}
+	if target.SenderIsBot {
+		config.TargetType = sibylSystem.EntityTypeBot
+	}
+
+	_, err := a.client.Report(target.SenderId, args, config)
+	if err != nil {
+		return "", err
+	}
+
+	return a.localize(MsgKeyReportSent), nil
+}
+
+func (a *Adapter) handleInfo(ctx context.Context, msg *Message, args string) (string, error) {
+	userId, _ := getTargetUser(msg, args)
+	if args == "" && userId == 0 {
+		userId = msg.SenderId
+	}
+
+	if userId == 0 {
+		return a.localize(MsgKeyInfoUsage), nil
+	}
+
+	info, err := a.client.GetInfoWithContext(ctx, userId)
+	if err != nil {
+		return "", err
+	}
+
+	return a.renderer.RenderInfo(info)
+}
+
+func (a *Adapter) localize(key string) string {
+	f := a.renderer.GetFormatter()
+	return f.Escape(sibylSystem.Localize(a.renderer.GetLanguage(), key))
+}
+
+func (a *Adapter) getParseMode() string {
+	return a.renderer.GetFormatter().GetParseMode()
+}
+
+//---------------------------------------------------------
+
+func (b *HttpBotApi) BanChatMember(ctx context.Context, chatId, userId int64) error {
+	v := getMemberValues(chatId, userId)
+	return b.call(ctx, "banChatMember", v)
+}
+
+func (b *HttpBotApi) UnbanChatMember(ctx context.Context, chatId, userId int64) error {
+	v := getMemberValues(chatId, userId)
+	v.Set("only_if_banned", "true")
+	return b.call(ctx, "unbanChatMember", v)
+}
+
+func (b *HttpBotApi) RestrictChatMember(ctx context.Context, chatId, userId int64, canSend bool) error {
+	value := strconv.FormatBool(canSend)
+	v := getMemberValues(chatId, userId)
+	v.Set("permissions", `{"can_send_messages":`+value+
+		`,"can_send_media_messages":`+value+
+		`,"can_send_polls":`+value+
+		`,"can_send_other_messages":`+value+
+		`,"can_add_web_page_previews":`+value+`}`)
+	return b.call(ctx, "restrictChatMember", v)
+}
+
+func (b *HttpBotApi) SendMessage(ctx context.Context, chatId int64, text, parseMode string, replyTo int64) error {
+	v := urlLib.Values{}
+	v.Set("chat_id", strconv.FormatInt(chatId, 10))
+	v.Set("text", text)
+	if parseMode != "" {
+		v.Set("parse_mode", parseMode)
+	}
+
+	if replyTo != 0 {
+		v.Set("reply_to_message_id", strconv.FormatInt(replyTo, 10))
+		v.Set("allow_sending_without_reply", "true")
+	}
+
+	return b.call(ctx, "sendMessage", v)
+}
+
+func (b *HttpBotApi) call(ctx context.Context, method string, v urlLib.Values) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	url := b.apiUrl + "/bot" + b.token + "/" + method
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(v.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	result := new(botApiResponse)
+	err = json.NewDecoder(resp.Body).Decode(result)
+	if err != nil {
+		return err
+	}
+
+	if !result.Ok {
+		return &BotApiError{
+			Method:      method,
+			Code:        result.ErrorCode,
+			Description: result.Description,
+		}
+	}
+
+	return nil
+}
+
+//---------------------------------------------------------
+
+func (e *BotApiError) Error() string {
+	return "bot api: " + e.Method + ": " + strconv.Itoa(e.Code) + " " + e.Description
+}
@@ -0,0 +1,121 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package sibylBot
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/sibylGuard"
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/sibylRender"
+)
+
+// BotApi is the part of the telegram bot api used by the adapter. it's
+// implemented by HttpBotApi, and can be implemented on top of the bot
+// object of any telegram library (e.g. gotgbot's *gotgbot.Bot).
+type BotApi interface {
+	BanChatMember(ctx context.Context, chatId, userId int64) error
+	UnbanChatMember(ctx context.Context, chatId, userId int64) error
+	// RestrictChatMember mutes the user if canSend is false, and lifts
+	// the restriction otherwise.
+	RestrictChatMember(ctx context.Context, chatId, userId int64, canSend bool) error
+	SendMessage(ctx context.Context, chatId int64, text, parseMode string, replyTo int64) error
+}
+
+// HttpBotApi is a minimal BotApi which talks to the bot api server
+// directly over http.
+type HttpBotApi struct {
+	token      string
+	apiUrl     string
+	httpClient *http.Client
+}
+
+// BotApiError is returned by HttpBotApi when the bot api server
+// rejects a request.
+type BotApiError struct {
+	Method      string
+	Code        int
+	Description string
+}
+
+// Adapter plugs a sibyl client into a telegram bot: it checks the new
+// members and the senders of messages using a guard, executes the
+// decisions using the bot api, and handles the sibyl commands.
+//
+// the adapter works on Message, so a bot built on any library only has
+// to convert its updates; the sibylGotgbot package does that for the
+// dispatcher of gotgbot.
+type Adapter struct {
+	client    sibylSystem.SibylClient
+	bot       BotApi
+	guard     *sibylGuard.Guard
+	renderer  *sibylRender.Renderer
+	quiet     bool
+	enforcers map[int64]bool
+	commands  map[string]CommandHandler
+}
+
+type AdapterConfig struct {
+	// Guard checks the users; if nil, a guard with the default config
+	// is used.
+	Guard *sibylGuard.Guard
+
+	// Renderer renders the replies of commands; if nil, a MarkdownV2
+	// renderer is used.
+	Renderer *sibylRender.Renderer
+
+	// Quiet disables announcing the decisions of the guard in the chat.
+	Quiet bool
+
+	// Enforcers are the users allowed to use /scan and /report; since the
+	// reports are sent using the token of the bot and /scan executes the
+	// decision of the guard, nobody else can use them.
+	Enforcers []int64
+}
+
+type HttpBotApiConfig struct {
+	// ApiUrl is the url of the bot api server; if empty, DefaultBotApiUrl
+	// is used.
+	ApiUrl string
+
+	// HttpClient is used for sending the requests; if nil,
+	// http.DefaultClient is used.
+	HttpClient *http.Client
+}
+
+// Message is a telegram message, with only the fields used by the adapter.
+type Message struct {
+	ChatId      int64
+	MessageId   int64
+	SenderId    int64
+	SenderIsBot bool
+	Text        string
+
+	// ReplyTo is the message which this message replies to, if any.
+	ReplyTo *Message
+
+	// NewMembers are the users who have joined the chat; it's set for
+	// the service messages of joins.
+	NewMembers []Member
+}
+
+// Member is a user who has joined a chat.
+type Member struct {
+	UserId int64
+	IsBot  bool
+}
+
+// CommandHandler handles a command; args is the text after the command.
+// the returned text is sent as a reply, if it's not empty.
+type CommandHandler func(ctx context.Context, msg *Message, args string) (string, error)
+
+// botApiResponse is the common part of the responses of the bot api.
+type botApiResponse struct {
+	Ok          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
+}
@@ -0,0 +1,22 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package sibylBot
+
+import "errors"
+
+var (
+	ErrNoClient = errors.New("a sibyl client is required for the adapter")
+	ErrNoBotApi = errors.New("a bot api is required for the adapter")
+)
+
+var englishMessages = map[string]string{
+	MsgKeyScanUsage:     "Reply to a message of the user or pass their id: /scan <id>",
+	MsgKeyReportUsage:   "Reply to a message of the user with /report <reason>",
+	MsgKeyReportSent:    "The user has been reported to sibyl",
+	MsgKeyInfoUsage:     "Reply to a message of the user or pass their id: /sibylinfo <id>",
+	MsgKeyCommandFailed: "Sibyl couldn't handle the command",
+	MsgKeyNotAllowed:    "You are not allowed to use this command",
+}
@@ -0,0 +1,14 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package sibylGotgbot
+
+// HandlerName is the name of the handler in the dispatcher of gotgbot.
+const HandlerName = "sibylGuard"
+
+// DefaultHandlerGroup is the suggested group of the handler; it runs
+// before the handlers of the bot (which are in group 0 by default), so
+// the messages of removed users never reach them.
+const DefaultHandlerGroup = -1
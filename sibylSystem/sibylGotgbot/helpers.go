@@ -0,0 +1,180 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package sibylGotgbot
+
+import (
+	"context"
+	"time"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/sibylBot"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters"
+)
+
+// NewBotApi returns a sibylBot.BotApi which sends the requests using the
+// gotgbot bot.
+func NewBotApi(bot *gotgbot.Bot) *BotApi {
+	return &BotApi{
+		bot: bot,
+	}
+}
+
+// NewAdapter returns a sibyl adapter which executes its decisions using
+// the gotgbot bot.
+func NewAdapter(client sibylSystem.SibylClient, bot *gotgbot.Bot, config *sibylBot.AdapterConfig) (*sibylBot.Adapter, error) {
+	if bot == nil {
+		return nil, sibylBot.ErrNoBotApi
+	}
+
+	return sibylBot.NewAdapter(client, NewBotApi(bot), config)
+}
+
+// NewHandler returns a new handler which passes the messages of the
+// updates to the adapter; it should be added to the dispatcher before
+// the other handlers of the bot, e.g. in DefaultHandlerGroup.
+func NewHandler(adapter *sibylBot.Adapter, config *HandlerConfig) (*Handler, error) {
+	if adapter == nil {
+		return nil, ErrNoAdapter
+	}
+
+	if config == nil {
+		config = GetDefaultHandlerConfig()
+	}
+
+	return &Handler{
+		adapter: adapter,
+		filter:  config.Filter,
+		timeout: config.Timeout,
+	}, nil
+}
+
+func GetDefaultHandlerConfig() *HandlerConfig {
+	return &HandlerConfig{}
+}
+
+// GetCommandHandlers returns a gotgbot command handler for each command
+// of the adapter (/scan, /report, /sibylinfo and the added ones), for
+// the bots which only want the commands and not the checks of Handler.
+func GetCommandHandlers(adapter *sibylBot.Adapter) []ext.Handler {
+	names := adapter.GetCommands()
+	allHandlers := make([]ext.Handler, 0, len(names))
+	for _, name := range names {
+		allHandlers = append(allHandlers, handlers.NewCommand(name, getCommandResponse(adapter)))
+	}
+
+	return allHandlers
+}
+
+func getCommandResponse(adapter *sibylBot.Adapter) handlers.Response {
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		_, err := adapter.HandleCommand(context.Background(), ConvertMessage(ctx.EffectiveMessage))
+		return err
+	}
+}
+
+// ConvertMessage converts a gotgbot message to the message of the
+// adapter; the caption is used as the text of media messages.
+func ConvertMessage(msg *gotgbot.Message) *sibylBot.Message {
+	if msg == nil {
+		return nil
+	}
+
+	converted := &sibylBot.Message{
+		ChatId:    msg.Chat.Id,
+		MessageId: msg.MessageId,
+		Text:      msg.Text,
+		ReplyTo:   ConvertMessage(msg.ReplyToMessage),
+	}
+
+	if converted.Text == "" {
+		converted.Text = msg.Caption
+	}
+
+	if msg.From != nil {
+		converted.SenderId = msg.From.Id
+		converted.SenderIsBot = msg.From.IsBot
+	}
+
+	for _, current := range msg.NewChatMembers {
+		converted.NewMembers = append(converted.NewMembers, sibylBot.Member{
+			UserId: current.Id,
+			IsBot:  current.IsBot,
+		})
+	}
+
+	return converted
+}
+
+// FilterEnforcers returns a filter which matches the messages sent by the
+// enforcers of the adapter.
+func FilterEnforcers(adapter *sibylBot.Adapter) filters.Message {
+	return func(msg *gotgbot.Message) bool {
+		return msg.From != nil && adapter.IsEnforcer(msg.From.Id)
+	}
+}
+
+// FilterCommands returns a filter which matches the messages containing
+// one of the commands of the adapter.
+func FilterCommands(adapter *sibylBot.Adapter) filters.Message {
+	return func(msg *gotgbot.Message) bool {
+		text := msg.Text
+		if text == "" {
+			text = msg.Caption
+		}
+
+		name, _, ok := sibylBot.ParseCommand(text)
+		return ok && adapter.HasCommand(name)
+	}
+}
+
+// FilterNewMembers is a filter which matches the messages about new
+// members joining the chat.
+func FilterNewMembers(msg *gotgbot.Message) bool {
+	return len(msg.NewChatMembers) != 0
+}
+
+// getRequestOpts returns the request options of a call with the context;
+// gotgbot doesn't take a context, so only its deadline is passed on.
+func getRequestOpts(ctx context.Context) *gotgbot.RequestOpts {
+	if ctx == nil {
+		return nil
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return nil
+	}
+
+	return &gotgbot.RequestOpts{
+		Timeout: time.Until(deadline),
+	}
+}
+
+func getPermissions(canSend bool) gotgbot.ChatPermissions {
+	return gotgbot.ChatPermissions{
+		CanSendMessages:       canSend,
+		CanSendAudios:         canSend,
+		CanSendDocuments:      canSend,
+		CanSendPhotos:         canSend,
+		CanSendVideos:         canSend,
+		CanSendVideoNotes:     canSend,
+		CanSendVoiceNotes:     canSend,
+		CanSendPolls:          canSend,
+		CanSendOtherMessages:  canSend,
+		CanAddWebPagePreviews: canSend,
+	}
+}
+
+func getContextErr(ctx context.Context) error {
+	if ctx == nil {
+		return nil
+	}
+
+	return ctx.Err()
+}
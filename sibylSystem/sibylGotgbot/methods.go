@@ -0,0 +1,110 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package sibylGotgbot
+
+import (
+	"context"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/sibylBot"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+func (b *BotApi) BanChatMember(ctx context.Context, chatId, userId int64) error {
+	if err := getContextErr(ctx); err != nil {
+		return err
+	}
+
+	_, err := b.bot.BanChatMember(chatId, userId, &gotgbot.BanChatMemberOpts{
+		RequestOpts: getRequestOpts(ctx),
+	})
+	return err
+}
+
+func (b *BotApi) UnbanChatMember(ctx context.Context, chatId, userId int64) error {
+	if err := getContextErr(ctx); err != nil {
+		return err
+	}
+
+	_, err := b.bot.UnbanChatMember(chatId, userId, &gotgbot.UnbanChatMemberOpts{
+		OnlyIfBanned: true,
+		RequestOpts:  getRequestOpts(ctx),
+	})
+	return err
+}
+
+func (b *BotApi) RestrictChatMember(ctx context.Context, chatId, userId int64, canSend bool) error {
+	if err := getContextErr(ctx); err != nil {
+		return err
+	}
+
+	_, err := b.bot.RestrictChatMember(chatId, userId, getPermissions(canSend), &gotgbot.RestrictChatMemberOpts{
+		RequestOpts: getRequestOpts(ctx),
+	})
+	return err
+}
+
+func (b *BotApi) SendMessage(ctx context.Context, chatId int64, text, parseMode string, replyTo int64) error {
+	if err := getContextErr(ctx); err != nil {
+		return err
+	}
+
+	_, err := b.bot.SendMessage(chatId, text, &gotgbot.SendMessageOpts{
+		ParseMode:                parseMode,
+		ReplyToMessageId:         replyTo,
+		AllowSendingWithoutReply: replyTo != 0,
+		RequestOpts:              getRequestOpts(ctx),
+	})
+	return err
+}
+
+// GetBot returns the gotgbot bot used for the requests.
+func (b *BotApi) GetBot() *gotgbot.Bot {
+	return b.bot
+}
+
+//---------------------------------------------------------
+
+// CheckUpdate returns true if the update has a message which matches the
+// filter of the handler; edited messages and channel posts are ignored.
+func (h *Handler) CheckUpdate(b *gotgbot.Bot, ctx *ext.Context) bool {
+	if ctx.Message == nil {
+		return false
+	}
+
+	return h.filter == nil || h.filter(ctx.Message)
+}
+
+// HandleUpdate passes the message to the adapter; it returns ext.EndGroups
+// if the sender (or a new member) has been removed from the chat.
+func (h *Handler) HandleUpdate(b *gotgbot.Bot, ctx *ext.Context) error {
+	c := context.Background()
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		c, cancel = context.WithTimeout(c, h.timeout)
+		defer cancel()
+	}
+
+	allowed, err := h.adapter.ProcessMessage(c, ConvertMessage(ctx.Message))
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		return ext.EndGroups
+	}
+
+	return nil
+}
+
+func (h *Handler) Name() string {
+	return HandlerName
+}
+
+// GetAdapter returns the adapter which handles the messages.
+func (h *Handler) GetAdapter() *sibylBot.Adapter {
+	return h.adapter
+}
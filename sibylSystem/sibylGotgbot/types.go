@@ -0,0 +1,41 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package sibylGotgbot
+
+import (
+	"time"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/sibylBot"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters"
+)
+
+// BotApi implements sibylBot.BotApi using a gotgbot bot, so the adapter
+// uses the same bot (and its api url and http client) as the dispatcher.
+type BotApi struct {
+	bot *gotgbot.Bot
+}
+
+// Handler is a handler for the dispatcher of gotgbot, which passes the
+// messages to a sibyl adapter: it checks the new members and the senders,
+// executes the decisions, and handles the sibyl commands. when the sender
+// isn't allowed in the chat, the handler ends the groups, so the other
+// handlers of the bot never see the message.
+type Handler struct {
+	adapter *sibylBot.Adapter
+	filter  filters.Message
+	timeout time.Duration
+}
+
+type HandlerConfig struct {
+	// Filter limits the messages passed to the adapter; nil means all of
+	// the messages.
+	Filter filters.Message
+
+	// Timeout is the timeout of handling a single message, including the
+	// requests to sibyl and the bot api; zero means no timeout.
+	Timeout time.Duration
+}
@@ -0,0 +1,12 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package sibylGotgbot
+
+import "errors"
+
+var (
+	ErrNoAdapter = errors.New("a sibyl adapter is required for the handler")
+)
@@ -0,0 +1,48 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package sibylGuard
+
+import "time"
+
+const (
+	// EventJoin is sent when a user joins the chat (or is added to it).
+	EventJoin EventKind = iota
+	// EventMessage is sent when a user sends a message to the chat.
+	EventMessage
+)
+
+const (
+	ActionAllow Action = iota
+	ActionMute
+	ActionKick
+	ActionBan
+)
+
+const (
+	// FailOpen allows the users when their info can't be fetched.
+	FailOpen FailurePolicy = iota
+	// FailClosed applies GuardConfig.FailClosedAction to the users when
+	// their info can't be fetched.
+	FailClosed
+)
+
+const (
+	// DefaultLookupTimeout is the default timeout of fetching the info
+	// of a user.
+	DefaultLookupTimeout = 5 * time.Second
+)
+
+// TemplateDecision is the name of the template used for rendering the
+// reason of decisions.
+const TemplateDecision = "guard_decision"
+
+// keys of the messages used by the default template.
+const (
+	MsgKeyDecisionBan    = "guard.decision.ban"
+	MsgKeyDecisionKick   = "guard.decision.kick"
+	MsgKeyDecisionMute   = "guard.decision.mute"
+	MsgKeyDecisionFailed = "guard.decision.failed"
+)
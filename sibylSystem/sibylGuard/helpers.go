@@ -0,0 +1,120 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package sibylGuard
+
+import (
+	"strings"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/sibylRender"
+	"golang.org/x/text/language"
+)
+
+func init() {
+	_ = sibylSystem.RegisterLocale(language.English, englishMessages)
+}
+
+// NewGuard returns a new guard which checks the users using the client.
+// enabling the info cache of the client (SibylConfig.InfoCacheTTL) is
+// recommended, since the same users are checked on every message.
+func NewGuard(client sibylSystem.SibylClient, config *GuardConfig) (*Guard, error) {
+	if client == nil {
+		return nil, ErrNoClient
+	}
+
+	if config == nil {
+		config = GetDefaultGuardConfig()
+	}
+
+	g := &Guard{
+		client:           client,
+		policy:           config.Policy,
+		failurePolicy:    config.FailurePolicy,
+		failClosedAction: config.FailClosedAction,
+		timeout:          config.Timeout,
+		renderer:         config.Renderer,
+		allowlist:        config.Allowlist,
+	}
+
+	if g.policy == nil {
+		g.policy = DefaultPolicy
+	}
+
+	if g.failClosedAction == ActionAllow {
+		g.failClosedAction = ActionMute
+	}
+
+	if g.timeout <= 0 {
+		g.timeout = DefaultLookupTimeout
+	}
+
+	if g.renderer == nil {
+		var err error
+		g.renderer, err = sibylRender.NewRenderer(sibylRender.FormatPlain, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !g.renderer.HasTemplate(TemplateDecision) {
+		err := g.renderer.SetTemplate(TemplateDecision, defaultDecisionTemplate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return g, nil
+}
+
+func GetDefaultGuardConfig() *GuardConfig {
+	return &GuardConfig{
+		Policy:           DefaultPolicy,
+		FailurePolicy:    FailOpen,
+		FailClosedAction: ActionMute,
+		Timeout:          DefaultLookupTimeout,
+	}
+}
+
+// ParseAction parses the name of an action, e.g. "ban".
+func ParseAction(value string) (Action, error) {
+	action, ok := actionNames[strings.ToLower(strings.TrimSpace(value))]
+	if !ok {
+		return ActionAllow, ErrInvalidAction
+	}
+
+	return action, nil
+}
+
+// DefaultPolicy bans all of the users who are banned in sibyl.
+func DefaultPolicy(event *Event, info *sibylSystem.GetInfoResult) Action {
+	if info.Banned {
+		return ActionBan
+	}
+
+	return ActionAllow
+}
+
+// BandPolicy decides by the crime band of the banned users: lethal
+// eliminators are banned, paralyzers are muted, and latent criminals are
+// kicked when they join, but allowed to keep sending messages.
+func BandPolicy(event *Event, info *sibylSystem.GetInfoResult) Action {
+	if !info.Banned {
+		return ActionAllow
+	}
+
+	switch info.CrimeCoefficient.GetBand() {
+	case sibylSystem.CrimeBandLethalEliminator:
+		return ActionBan
+	case sibylSystem.CrimeBandParalyzer:
+		return ActionMute
+	case sibylSystem.CrimeBandLatentCriminal:
+		if event.Kind == EventJoin {
+			return ActionKick
+		}
+	}
+
+	return ActionAllow
+}
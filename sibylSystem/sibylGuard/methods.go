@@ -0,0 +1,167 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package sibylGuard
+
+import (
+	"context"
+)
+
+// Check checks the user of the event and returns the decision; it never
+// returns nil. users in the allowlist of the guard are always allowed.
+// if the info of the user can't be fetched, the action is decided by the
+// failure policy and the error is set in Decision.Err.
+func (g *Guard) Check(ctx context.Context, event *Event) *Decision {
+	decision := &Decision{
+		Event: event,
+	}
+
+	if event == nil || event.UserId == 0 {
+		decision.Err = ErrInvalidEvent
+		return decision
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+
+	info, err := g.client.GetInfoWithContext(ctx, event.UserId)
+	if g.allowlist != nil && g.allowlist.CheckAllowed(event.ChatId, event.UserId, info) {
+		decision.Info = info
+		decision.Err = err
+		decision.Allowlisted = true
+	} else if err != nil {
+		decision.Err = err
+		if g.failurePolicy == FailClosed {
+			decision.Action = g.failClosedAction
+		}
+	} else {
+		decision.Info = info
+		decision.Action = g.policy(event, info)
+	}
+
+	if decision.Action != ActionAllow {
+		decision.Reason, err = g.renderer.Render(TemplateDecision, decision)
+		if err != nil && decision.Err == nil {
+			decision.Err = err
+		}
+	}
+
+	if g.onDecision != nil {
+		g.onDecision(decision)
+	}
+
+	return decision
+}
+
+// CheckJoin checks a user who has joined the chat.
+func (g *Guard) CheckJoin(ctx context.Context, chatId, userId int64, isBot bool) *Decision {
+	return g.Check(ctx, &Event{
+		ChatId: chatId,
+		UserId: userId,
+		IsBot:  isBot,
+		Kind:   EventJoin,
+	})
+}
+
+// CheckMessage checks the sender of a message.
+func (g *Guard) CheckMessage(ctx context.Context, chatId, userId int64, isBot bool) *Decision {
+	return g.Check(ctx, &Event{
+		ChatId: chatId,
+		UserId: userId,
+		IsBot:  isBot,
+		Kind:   EventMessage,
+	})
+}
+
+// SetOnDecision sets a callback which is called with all of the
+// decisions, e.g. for logging them.
+func (g *Guard) SetOnDecision(fn func(*Decision)) {
+	g.onDecision = fn
+}
+
+//---------------------------------------------------------
+
+// IsAllowed returns true if nothing should be done with the user.
+func (d *Decision) IsAllowed() bool {
+	return d.Action == ActionAllow
+}
+
+// IsFailure returns true if the decision has been made by the failure
+// policy, since the info of the user couldn't be fetched.
+func (d *Decision) IsFailure() bool {
+	return d.Err != nil && d.Info == nil
+}
+
+// GetMessageKey returns the key of the message describing the decision.
+func (d *Decision) GetMessageKey() string {
+	if d.IsFailure() && d.Action != ActionAllow {
+		return MsgKeyDecisionFailed
+	}
+
+	switch d.Action {
+	case ActionBan:
+		return MsgKeyDecisionBan
+	case ActionKick:
+		return MsgKeyDecisionKick
+	case ActionMute:
+		return MsgKeyDecisionMute
+	default:
+		return ""
+	}
+}
+
+//---------------------------------------------------------
+
+// MarshalText marshals the action as its name.
+func (a Action) MarshalText() ([]byte, error) {
+	if a < ActionAllow || a > ActionBan {
+		return nil, ErrInvalidAction
+	}
+
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText parses the action from its name.
+func (a *Action) UnmarshalText(text []byte) error {
+	action, err := ParseAction(string(text))
+	if err != nil {
+		return err
+	}
+
+	*a = action
+	return nil
+}
+
+func (a Action) String() string {
+	switch a {
+	case ActionAllow:
+		return "allow"
+	case ActionMute:
+		return "mute"
+	case ActionKick:
+		return "kick"
+	case ActionBan:
+		return "ban"
+	default:
+		return "unknown"
+	}
+}
+
+//---------------------------------------------------------
+
+func (k EventKind) String() string {
+	switch k {
+	case EventJoin:
+		return "join"
+	case EventMessage:
+		return "message"
+	default:
+		return "unknown"
+	}
+}
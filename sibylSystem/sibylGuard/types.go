@@ -0,0 +1,99 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package sibylGuard
+
+import (
+	"time"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/sibylRender"
+)
+
+type EventKind int
+type Action int
+type FailurePolicy int
+
+// Policy decides what should be done with the user of the event; info
+// is never nil.
+type Policy func(event *Event, info *sibylSystem.GetInfoResult) Action
+
+// Allowlist contains the local overrides of the sibyl's verdicts, e.g.
+// the Allowlist of the banAllow package.
+type Allowlist interface {
+	// CheckAllowed returns true if the user is allowed in the chat,
+	// whatever its sibyl info is. info is nil if it couldn't be fetched.
+	CheckAllowed(chatId, userId int64, info *sibylSystem.GetInfoResult) bool
+}
+
+// Guard checks the users of chat events against sibyl and decides what
+// should be done with them. it doesn't depend on any telegram library;
+// executing the decision is up to the caller.
+type Guard struct {
+	client           sibylSystem.SibylClient
+	policy           Policy
+	failurePolicy    FailurePolicy
+	failClosedAction Action
+	timeout          time.Duration
+	renderer         *sibylRender.Renderer
+	allowlist        Allowlist
+
+	onDecision func(*Decision)
+}
+
+type GuardConfig struct {
+	// Policy decides the action for the users which have been fetched
+	// successfully. if nil, DefaultPolicy is used.
+	Policy Policy
+
+	// FailurePolicy decides what happens when the info of a user can't
+	// be fetched. the default is FailOpen.
+	FailurePolicy FailurePolicy
+
+	// FailClosedAction is the action taken with FailClosed policy.
+	// the default is ActionMute.
+	FailClosedAction Action
+
+	// Timeout is the timeout of fetching the info of a user. if zero,
+	// DefaultLookupTimeout is used.
+	Timeout time.Duration
+
+	// Renderer renders the reason of decisions. TemplateDecision is added
+	// to it if it doesn't exist. if nil, a plain text renderer is used.
+	Renderer *sibylRender.Renderer
+
+	// Allowlist is consulted before the policy; allowlisted users are
+	// always allowed. if nil, no user is allowlisted.
+	Allowlist Allowlist
+}
+
+// Event is a chat event which should be checked by the guard.
+type Event struct {
+	ChatId int64
+	UserId int64
+	IsBot  bool
+	Kind   EventKind
+}
+
+// Decision is the result of checking an event.
+type Decision struct {
+	Event  *Event
+	Action Action
+
+	// Info is the info of the user; it's nil if it couldn't be fetched.
+	Info *sibylSystem.GetInfoResult
+
+	// Reason is the rendered reason of the decision; it's empty for
+	// ActionAllow.
+	Reason string
+
+	// Allowlisted is true if the user has been allowed by the allowlist
+	// of the guard.
+	Allowlisted bool
+
+	// Err is the error of fetching the info of the user; if it's not nil,
+	// Action has been decided by the failure policy.
+	Err error
+}
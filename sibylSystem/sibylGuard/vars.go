@@ -0,0 +1,38 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package sibylGuard
+
+import "errors"
+
+var (
+	ErrNoClient      = errors.New("a sibyl client is required for the guard")
+	ErrInvalidEvent  = errors.New("event has no valid user id")
+	ErrInvalidAction = errors.New("unknown guard action")
+)
+
+// actionNames maps the names of actions to them, for parsing.
+var actionNames = map[string]Action{
+	"allow": ActionAllow,
+	"mute":  ActionMute,
+	"kick":  ActionKick,
+	"ban":   ActionBan,
+}
+
+var englishMessages = map[string]string{
+	MsgKeyDecisionBan:    "has been banned",
+	MsgKeyDecisionKick:   "has been kicked",
+	MsgKeyDecisionMute:   "has been muted",
+	MsgKeyDecisionFailed: "couldn't be checked by sibyl",
+}
+
+// defaultDecisionTemplate is the built-in template of TemplateDecision;
+// it's executed on a *Decision.
+var defaultDecisionTemplate = `{{mention .Event.UserId .Event.UserId}} {{t .GetMessageKey}}
+{{- if .Info}}{{if .Info.Banned}}
+{{t "render.reason"}}: {{.Info.Reason}}
+{{t "render.flags"}}: {{flags .Info.BanFlags}}
+{{t "render.coefficient"}}: {{mono .Info.CrimeCoefficient}} ({{band .Info.CrimeCoefficient}})
+{{- end}}{{end}}`
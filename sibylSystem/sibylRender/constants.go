@@ -0,0 +1,67 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package sibylRender
+
+const (
+	// FormatPlain renders plain text without any formatting.
+	FormatPlain Format = iota
+	// FormatMarkdownV2 renders text for the MarkdownV2 parse mode of telegram.
+	FormatMarkdownV2
+	// FormatHTML renders text for the HTML parse mode of telegram.
+	FormatHTML
+)
+
+// names of the built-in templates.
+const (
+	TemplateInfo        = "info"
+	TemplateBan         = "ban"
+	TemplateToken       = "token"
+	TemplateGeneralInfo = "general_info"
+	TemplateStats       = "stats"
+)
+
+// markers of the formatted parts of the output; everything outside of
+// them is escaped after the template is executed, so the literal text
+// of templates doesn't need any escaping.
+const (
+	markStart = '\uE000'
+	markEnd   = '\uE001'
+
+	markerRunes = string(markStart) + string(markEnd)
+)
+
+// keys of the labels used by the default templates.
+const (
+	MsgKeyUser            = "render.user"
+	MsgKeyBanned          = "render.banned"
+	MsgKeyReason          = "render.reason"
+	MsgKeyMessage         = "render.message"
+	MsgKeyFlags           = "render.flags"
+	MsgKeyCoefficient     = "render.coefficient"
+	MsgKeyBannedBy        = "render.banned-by"
+	MsgKeyDate            = "render.date"
+	MsgKeySource          = "render.source"
+	MsgKeySourceLink      = "render.source-link"
+	MsgKeyTargetType      = "render.target-type"
+	MsgKeyBanAdded        = "render.ban-added"
+	MsgKeyBanUpdated      = "render.ban-updated"
+	MsgKeyToken           = "render.token"
+	MsgKeyPermission      = "render.permission"
+	MsgKeyDivision        = "render.division"
+	MsgKeyAssignedBy      = "render.assigned-by"
+	MsgKeyAssignedReason  = "render.assigned-reason"
+	MsgKeyAssignedAt      = "render.assigned-at"
+	MsgKeyCreatedAt       = "render.created-at"
+	MsgKeyAcceptedReports = "render.accepted-reports"
+	MsgKeyDeniedReports   = "render.denied-reports"
+	MsgKeyGeneralInfo     = "render.general-info"
+	MsgKeyStats           = "render.stats"
+	MsgKeyBannedCount     = "render.stats.banned"
+	MsgKeyCloudyCount     = "render.stats.cloudy"
+	MsgKeyTokenCount      = "render.stats.tokens"
+	MsgKeyInspectorsCount = "render.stats.inspectors"
+	MsgKeyEnforcersCount  = "render.stats.enforcers"
+)
@@ -0,0 +1,296 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package sibylRender
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+	"golang.org/x/text/language"
+)
+
+func init() {
+	_ = sibylSystem.RegisterLocale(language.English, englishMessages)
+}
+
+// NewRenderer returns a new renderer for the given format, with the
+// built-in templates and the templates of the config.
+func NewRenderer(format Format, config *RendererConfig) (*Renderer, error) {
+	if config == nil {
+		config = GetDefaultRendererConfig()
+	}
+
+	formatter := config.Formatter
+	if formatter == nil {
+		formatter = formatters[format]
+		if formatter == nil {
+			return nil, ErrUnknownFormat
+		}
+	}
+
+	lang := config.Language
+	if lang == language.Und {
+		lang = language.English
+	}
+
+	r := &Renderer{
+		mut:       &sync.RWMutex{},
+		format:    format,
+		formatter: formatter,
+		lang:      lang,
+	}
+	r.templates = template.New("").Funcs(r.getFuncMap())
+
+	for name, text := range defaultTemplates {
+		if _, ok := config.Templates[name]; ok {
+			continue
+		}
+
+		if err := r.SetTemplate(name, text); err != nil {
+			return nil, err
+		}
+	}
+
+	for name, text := range config.Templates {
+		if err := r.SetTemplate(name, text); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// GetDefaultRendererConfig returns the default config of renderers,
+// which renders the built-in templates in english.
+func GetDefaultRendererConfig() *RendererConfig {
+	return &RendererConfig{
+		Language: language.English,
+	}
+}
+
+// GetDefaultTemplate returns the text of a built-in template, so it can
+// be used as a base for a custom one.
+func GetDefaultTemplate(name string) string {
+	return defaultTemplates[name]
+}
+
+// mark marks the text as formatted, so it won't be escaped again.
+func mark(text string) markedText {
+	return markedText(string(markStart) + text + string(markEnd))
+}
+
+// toText converts a value passed to a template function to string; the
+// markers are removed from everything except the marked text.
+func toText(value interface{}) string {
+	switch v := value.(type) {
+	case markedText:
+		return string(v)
+	case string:
+		return stripMarkers(v)
+	case fmt.Stringer:
+		return stripMarkers(v.String())
+	default:
+		return stripMarkers(fmt.Sprint(value))
+	}
+}
+
+// sanitizeData returns a copy of the template data with the markers
+// removed from all of its strings, so the data can't mark its own text
+// as formatted.
+func sanitizeData(data interface{}) interface{} {
+	if data == nil {
+		return nil
+	}
+
+	value, changed := sanitizeValue(reflect.ValueOf(data), make(map[uintptr]bool))
+	if !changed {
+		return data
+	}
+
+	return value.Interface()
+}
+
+// sanitizeValue removes the markers from the strings of the value; the
+// value is only copied (and changed is true) if it contains a marker
+// somewhere. seen keeps the pointers which are being sanitized, so cycles
+// don't recurse forever.
+func sanitizeValue(value reflect.Value, seen map[uintptr]bool) (reflect.Value, bool) {
+	switch value.Kind() {
+	case reflect.String:
+		if !strings.ContainsAny(value.String(), markerRunes) {
+			return value, false
+		}
+
+		return reflect.ValueOf(stripMarkers(value.String())).Convert(value.Type()), true
+	case reflect.Ptr:
+		if value.IsNil() || seen[value.Pointer()] {
+			return value, false
+		}
+
+		seen[value.Pointer()] = true
+		elem, changed := sanitizeValue(value.Elem(), seen)
+		delete(seen, value.Pointer())
+		if !changed {
+			return value, false
+		}
+
+		sanitized := reflect.New(value.Type().Elem())
+		sanitized.Elem().Set(elem)
+		return sanitized, true
+	case reflect.Interface:
+		if value.IsNil() {
+			return value, false
+		}
+
+		elem, changed := sanitizeValue(value.Elem(), seen)
+		if !changed {
+			return value, false
+		}
+
+		sanitized := reflect.New(value.Type()).Elem()
+		sanitized.Set(elem)
+		return sanitized, true
+	case reflect.Struct:
+		var sanitized reflect.Value
+		for i := 0; i < value.NumField(); i++ {
+			if value.Type().Field(i).PkgPath != "" {
+				// unexported fields can't be set.
+				continue
+			}
+
+			field, changed := sanitizeValue(value.Field(i), seen)
+			if !changed {
+				continue
+			}
+
+			if !sanitized.IsValid() {
+				sanitized = reflect.New(value.Type()).Elem()
+				sanitized.Set(value)
+			}
+			sanitized.Field(i).Set(field)
+		}
+
+		return sanitizedOr(value, sanitized)
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return value, false
+		}
+
+		var sanitized reflect.Value
+		for i := 0; i < value.Len(); i++ {
+			elem, changed := sanitizeValue(value.Index(i), seen)
+			if !changed {
+				continue
+			}
+
+			if !sanitized.IsValid() {
+				sanitized = copyList(value)
+			}
+			sanitized.Index(i).Set(elem)
+		}
+
+		return sanitizedOr(value, sanitized)
+	case reflect.Map:
+		if value.IsNil() {
+			return value, false
+		}
+
+		var sanitized reflect.Value
+		iter := value.MapRange()
+		for iter.Next() {
+			key, elem := iter.Key(), iter.Value()
+			newKey, keyChanged := sanitizeValue(key, seen)
+			newElem, elemChanged := sanitizeValue(elem, seen)
+			if !keyChanged && !elemChanged {
+				continue
+			}
+
+			if !sanitized.IsValid() {
+				sanitized = reflect.MakeMapWithSize(value.Type(), value.Len())
+				for _, current := range value.MapKeys() {
+					sanitized.SetMapIndex(current, value.MapIndex(current))
+				}
+			}
+			sanitized.SetMapIndex(key, reflect.Value{})
+			sanitized.SetMapIndex(newKey, newElem)
+		}
+
+		return sanitizedOr(value, sanitized)
+	default:
+		return value, false
+	}
+}
+
+// sanitizedOr returns the sanitized copy if it has been made, otherwise
+// the original value.
+func sanitizedOr(value, sanitized reflect.Value) (reflect.Value, bool) {
+	if !sanitized.IsValid() {
+		return value, false
+	}
+
+	return sanitized, true
+}
+
+// copyList returns a settable copy of the slice or the array.
+func copyList(value reflect.Value) reflect.Value {
+	if value.Kind() == reflect.Array {
+		copied := reflect.New(value.Type()).Elem()
+		copied.Set(value)
+		return copied
+	}
+
+	copied := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+	reflect.Copy(copied, value)
+	return copied
+}
+
+// finalize escapes the parts of the text which aren't marked as
+// formatted, and removes the markers.
+func finalize(f sibylSystem.Formatter, text string) string {
+	if !strings.ContainsRune(text, markStart) {
+		return f.Escape(stripMarkers(text))
+	}
+
+	var result strings.Builder
+	depth := 0
+	start := 0
+	for i, current := range text {
+		switch current {
+		case markStart:
+			if depth == 0 {
+				result.WriteString(f.Escape(stripMarkers(text[start:i])))
+				start = i + len(string(markStart))
+			}
+			depth++
+		case markEnd:
+			if depth == 0 {
+				continue
+			}
+
+			depth--
+			if depth == 0 {
+				result.WriteString(stripMarkers(text[start:i]))
+				start = i + len(string(markEnd))
+			}
+		}
+	}
+
+	if depth == 0 {
+		result.WriteString(f.Escape(stripMarkers(text[start:])))
+	} else {
+		result.WriteString(stripMarkers(text[start:]))
+	}
+
+	return result.String()
+}
+
+func stripMarkers(text string) string {
+	return strings.NewReplacer(string(markStart), "", string(markEnd), "").Replace(text)
+}
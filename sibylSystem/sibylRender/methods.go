@@ -0,0 +1,224 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package sibylRender
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+	"golang.org/x/text/language"
+)
+
+// SetTemplate adds a template to the renderer, or replaces an existing one
+// with the same name. templates can use each other with the template action.
+func (r *Renderer) SetTemplate(name, text string) error {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	_, err := r.templates.New(name).Parse(text)
+	return err
+}
+
+// HasTemplate returns true if a template with the given name exists.
+func (r *Renderer) HasTemplate(name string) bool {
+	r.mut.RLock()
+	defer r.mut.RUnlock()
+
+	return r.templates.Lookup(name) != nil
+}
+
+// Render executes the template with the given name on data.
+func (r *Renderer) Render(name string, data interface{}) (string, error) {
+	r.mut.RLock()
+	defer r.mut.RUnlock()
+
+	tmpl := r.templates.Lookup(name)
+	if tmpl == nil {
+		return "", ErrUnknownTemplate
+	}
+
+	buf := new(bytes.Buffer)
+	err := tmpl.Execute(buf, sanitizeData(data))
+	if err != nil {
+		return "", err
+	}
+
+	return finalize(r.formatter, buf.String()), nil
+}
+
+// RenderInfo renders the result of GetInfo.
+func (r *Renderer) RenderInfo(info *sibylSystem.GetInfoResult) (string, error) {
+	return r.Render(TemplateInfo, info)
+}
+
+// RenderBanInfo renders a single ban record, using the same template
+// as RenderInfo.
+func (r *Renderer) RenderBanInfo(info *sibylSystem.BanInfo) (string, error) {
+	return r.Render(TemplateInfo, info)
+}
+
+// RenderBan renders the result of Ban.
+func (r *Renderer) RenderBan(result *sibylSystem.BanResult) (string, error) {
+	return r.Render(TemplateBan, result)
+}
+
+// RenderToken renders the info of a token; the hash of the token
+// isn't rendered by the built-in template.
+func (r *Renderer) RenderToken(info *sibylSystem.TokenInfo) (string, error) {
+	return r.Render(TemplateToken, info)
+}
+
+// RenderGeneralInfo renders the result of GetGeneralInfo.
+func (r *Renderer) RenderGeneralInfo(info *sibylSystem.GeneralInfoResult) (string, error) {
+	return r.Render(TemplateGeneralInfo, info)
+}
+
+// RenderStats renders the result of GetStats.
+func (r *Renderer) RenderStats(stats *sibylSystem.GetStatsResult) (string, error) {
+	return r.Render(TemplateStats, stats)
+}
+
+// GetFormatter returns the formatter used by the renderer.
+func (r *Renderer) GetFormatter() sibylSystem.Formatter {
+	return r.formatter
+}
+
+// GetFormat returns the format of the renderer.
+func (r *Renderer) GetFormat() Format {
+	return r.format
+}
+
+// GetLanguage returns the language of the renderer.
+func (r *Renderer) GetLanguage() language.Tag {
+	return r.lang
+}
+
+// getFuncMap returns the functions available to the templates. the
+// functions which format the text (e.g. bold) return marked text, so it's
+// not escaped again; all of the other functions return raw text.
+func (r *Renderer) getFuncMap() template.FuncMap {
+	funcs := template.FuncMap{
+		"link":       r.link,
+		"mention":    r.mention,
+		"flags":      r.flags,
+		"t":          r.localize,
+		"yesno":      r.yesOrNo,
+		"cuteFlags":  r.cuteFlags,
+		"flagName":   r.flagName,
+		"estimate":   r.estimate,
+		"band":       r.band,
+		"entity":     r.entity,
+		"permission": r.permission,
+		"date":       r.date,
+		"datetime":   r.datetime,
+		"ago":        r.ago,
+		"join":       strings.Join,
+	}
+
+	for name, style := range styleFuncs {
+		funcs[name] = r.styled(style)
+	}
+
+	return funcs
+}
+
+func (r *Renderer) styled(style sibylSystem.TextStyle) func(interface{}) markedText {
+	return func(value interface{}) markedText {
+		return mark(r.formatter.Style(style, finalize(r.formatter, toText(value))))
+	}
+}
+
+func (r *Renderer) link(text interface{}, url string) markedText {
+	return mark(r.formatter.Link(finalize(r.formatter, toText(text)), url))
+}
+
+func (r *Renderer) mention(text interface{}, userId int64) markedText {
+	if r.formatter.GetParseMode() == "" {
+		return mark(finalize(r.formatter, toText(text)))
+	}
+
+	return r.link(text, sibylSystem.GetUserMentionUrl(userId))
+}
+
+func (r *Renderer) flags(flags []sibylSystem.BanFlag) markedText {
+	return mark(sibylSystem.FormatFlagsWith(r.formatter, flags))
+}
+
+func (r *Renderer) localize(key string, args ...interface{}) string {
+	return sibylSystem.Localize(r.lang, key, args...)
+}
+
+func (r *Renderer) yesOrNo(value bool) string {
+	return sibylSystem.YesOrNoIn(r.lang, value)
+}
+
+func (r *Renderer) cuteFlags(flags []sibylSystem.BanFlag) string {
+	names := make([]string, len(flags))
+	for i, current := range flags {
+		names[i] = current.GetNameIn(r.lang)
+	}
+
+	return sibylSystem.JoinListIn(r.lang, names)
+}
+
+func (r *Renderer) flagName(flag string) string {
+	return sibylSystem.BanFlag(flag).GetNameIn(r.lang)
+}
+
+func (r *Renderer) estimate(c sibylSystem.CrimeCoefficient) string {
+	return c.EstimateIn(r.lang)
+}
+
+func (r *Renderer) band(c sibylSystem.CrimeCoefficient) string {
+	return c.GetBand().GetLabelInTag(r.lang)
+}
+
+func (r *Renderer) entity(e sibylSystem.EntityType) string {
+	return e.GetNameIn(r.lang)
+}
+
+func (r *Renderer) permission(p sibylSystem.UserPermission) string {
+	return p.GetNameIn(r.lang)
+}
+
+func (r *Renderer) date(t sibylSystem.SibylTime) string {
+	return t.FormatShort()
+}
+
+func (r *Renderer) datetime(t sibylSystem.SibylTime) string {
+	return t.String()
+}
+
+func (r *Renderer) ago(t sibylSystem.SibylTime) string {
+	return t.AgoIn(r.lang)
+}
+
+//---------------------------------------------------------
+
+// GetParseMode returns the telegram parse mode of the format; it's empty
+// for FormatPlain.
+func (f Format) GetParseMode() string {
+	if formatter := formatters[f]; formatter != nil {
+		return formatter.GetParseMode()
+	}
+
+	return ""
+}
+
+func (f Format) String() string {
+	switch f {
+	case FormatPlain:
+		return "plain"
+	case FormatMarkdownV2:
+		return "markdownv2"
+	case FormatHTML:
+		return "html"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,44 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package sibylRender
+
+import (
+	"sync"
+	"text/template"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+	"golang.org/x/text/language"
+)
+
+type Format int
+
+// Renderer renders the results of sibyl to messages using templates; the
+// templates are executed with a set of functions which format the text
+// according to the format of the renderer (see getFuncMap).
+type Renderer struct {
+	mut       *sync.RWMutex
+	format    Format
+	formatter sibylSystem.Formatter
+	lang      language.Tag
+	templates *template.Template
+}
+
+// markedText is the text returned by the template functions which format
+// their input; unlike other values, its markers are kept by toText.
+type markedText string
+
+// RendererConfig is the config of a Renderer.
+type RendererConfig struct {
+	// Language is the language of the labels and the localized values.
+	Language language.Tag
+
+	// Templates overrides the built-in templates (or adds new ones);
+	// the keys are the names of templates.
+	Templates map[string]string
+
+	// Formatter overrides the formatter of the format, if set.
+	Formatter sibylSystem.Formatter
+}
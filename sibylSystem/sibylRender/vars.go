@@ -0,0 +1,133 @@
+// sibylSystemGo library Project
+// Copyright (C) 2021-2022 ALiwoto
+// This file is subject to the terms and conditions defined in
+// file 'LICENSE', which is part of the source code.
+
+package sibylRender
+
+import (
+	"errors"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem"
+)
+
+var (
+	ErrUnknownFormat   = errors.New("unknown render format")
+	ErrUnknownTemplate = errors.New("template is not defined")
+)
+
+// englishMessages are the labels of the default templates; they are
+// registered in the catalog of sibylSystem, so they can be translated
+// the same way as the other messages.
+var englishMessages = map[string]string{
+	MsgKeyUser:            "User",
+	MsgKeyBanned:          "Banned",
+	MsgKeyReason:          "Reason",
+	MsgKeyMessage:         "Message",
+	MsgKeyFlags:           "Flags",
+	MsgKeyCoefficient:     "Crime coefficient",
+	MsgKeyBannedBy:        "Banned by",
+	MsgKeyDate:            "Date",
+	MsgKeySource:          "Source",
+	MsgKeySourceLink:      "link",
+	MsgKeyTargetType:      "Type",
+	MsgKeyBanAdded:        "User has been banned",
+	MsgKeyBanUpdated:      "Ban has been updated",
+	MsgKeyToken:           "Token info",
+	MsgKeyPermission:      "Permission",
+	MsgKeyDivision:        "Division",
+	MsgKeyAssignedBy:      "Assigned by",
+	MsgKeyAssignedReason:  "Assigned reason",
+	MsgKeyAssignedAt:      "Assigned at",
+	MsgKeyCreatedAt:       "Created at",
+	MsgKeyAcceptedReports: "Accepted reports",
+	MsgKeyDeniedReports:   "Denied reports",
+	MsgKeyGeneralInfo:     "General info",
+	MsgKeyStats:           "Sibyl stats",
+	MsgKeyBannedCount:     "Banned users",
+	MsgKeyCloudyCount:     "Cloudy users",
+	MsgKeyTokenCount:      "Tokens",
+	MsgKeyInspectorsCount: "Inspectors",
+	MsgKeyEnforcersCount:  "Enforcers",
+}
+
+// defaultTemplates are the built-in layouts; all of them can be
+// overridden using RendererConfig.Templates or Renderer.SetTemplate.
+var defaultTemplates = map[string]string{
+	TemplateInfo: `{{bold (t "render.user")}}: {{mention .UserId .UserId}}
+{{t "render.banned"}}: {{yesno .Banned}}
+{{- if .Banned}}
+{{t "render.reason"}}: {{.Reason}}
+{{- if .Message}}
+{{t "render.message"}}: {{.Message}}
+{{- end}}
+{{t "render.flags"}}: {{flags .BanFlags}}
+{{t "render.coefficient"}}: {{mono .CrimeCoefficient}} ({{band .CrimeCoefficient}})
+{{t "render.banned-by"}}: {{mono .BannedBy}}
+{{t "render.target-type"}}: {{entity .TargetType}}
+{{t "render.date"}}: {{date .Date}}
+{{- if .BanSourceUrl}}
+{{t "render.source"}}: {{link (t "render.source-link") .BanSourceUrl}}
+{{- end}}
+{{- end}}`,
+
+	TemplateBan: `{{if .PreviousBan}}{{bold (t "render.ban-updated")}}{{else}}{{bold (t "render.ban-added")}}{{end}}
+{{template "info" .CurrentBan}}`,
+
+	TemplateToken: `{{bold (t "render.token")}}
+{{t "render.user"}}: {{mention .UserId .UserId}}
+{{t "render.permission"}}: {{permission .Permission}}
+{{t "render.division"}}: {{mono .DivisionNum}}
+{{t "render.assigned-by"}}: {{mono .AssignedBy}}
+{{- if .AssignedReason}}
+{{t "render.assigned-reason"}}: {{.AssignedReason}}
+{{- end}}
+{{t "render.created-at"}}: {{date .CreatedAt}}
+{{t "render.accepted-reports"}}: {{mono .AcceptedReports}}
+{{t "render.denied-reports"}}: {{mono .DeniedReports}}`,
+
+	TemplateGeneralInfo: `{{bold (t "render.general-info")}}
+{{t "render.user"}}: {{mention .UserId .UserId}}
+{{t "render.permission"}}: {{permission .Permission}}
+{{t "render.division"}}: {{mono .Division}}
+{{t "render.assigned-by"}}: {{mono .AssignedBy}}
+{{- if .AssignedReason}}
+{{t "render.assigned-reason"}}: {{.AssignedReason}}
+{{- end}}
+{{t "render.assigned-at"}}: {{date .AssignedAt}}`,
+
+	TemplateStats: `{{bold (t "render.stats")}}
+{{t "render.stats.banned"}}: {{mono .BannedCount}}
+{{flagName "TROLLING"}}: {{mono .TrollingBanCount}}
+{{flagName "SPAM"}}: {{mono .SpamBanCount}}
+{{flagName "EVADE"}}: {{mono .EvadeBanCount}}
+{{flagName "CUSTOM"}}: {{mono .CustomBanCount}}
+{{flagName "PSYCHOHAZARD"}}: {{mono .PsychoHazardBanCount}}
+{{flagName "MALIMP"}}: {{mono .MalImpBanCount}}
+{{flagName "NSFW"}}: {{mono .NSFWBanCount}}
+{{flagName "SPAMBOT"}}: {{mono .SpamBotBanCount}}
+{{flagName "RAID"}}: {{mono .RaidBanCount}}
+{{flagName "MASSADD"}}: {{mono .MassAddBanCount}}
+{{t "render.stats.cloudy"}}: {{mono .CloudyCount}}
+{{t "render.stats.tokens"}}: {{mono .TokenCount}}
+{{t "render.stats.inspectors"}}: {{mono .InspectorsCount}}
+{{t "render.stats.enforcers"}}: {{mono .EnforcesCount}}`,
+}
+
+// formatters contains the formatter of each format.
+var formatters = map[Format]sibylSystem.Formatter{
+	FormatPlain:      sibylSystem.PlainFormatter{},
+	FormatMarkdownV2: sibylSystem.MarkdownFormatter{},
+	FormatHTML:       sibylSystem.HTMLFormatter{},
+}
+
+// styleFuncs maps the names of the style functions of templates
+// to their styles.
+var styleFuncs = map[string]sibylSystem.TextStyle{
+	"bold":      sibylSystem.StyleBold,
+	"italic":    sibylSystem.StyleItalic,
+	"underline": sibylSystem.StyleUnderline,
+	"strike":    sibylSystem.StyleStrike,
+	"spoiler":   sibylSystem.StyleSpoiler,
+	"mono":      sibylSystem.StyleMono,
+}
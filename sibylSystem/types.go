@@ -9,41 +9,363 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/AnimeKaizoku/ssg/ssg"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message/catalog"
 )
 
 type UserPermission int
 type EntityType int
 type PollingUniqueId uint64
 type BanFlag string
+type BanSeverity int
+type TextStyle int
+
+// CrimeCoefficient is the crime coefficient of a user, the higher it is,
+// the more dangerous the user is considered by sibyl.
+type CrimeCoefficient int64
+
+// CrimeBand is a range of crime coefficients which share the same
+// enforcement action.
+type CrimeBand int
+
+// EnforcementAction is the action suggested for a crime band.
+type EnforcementAction int
+
+// DegradationPolicy decides the verdict of BanChecker when the live
+// lookup of a user fails.
+type DegradationPolicy int
+
+// VerdictSource is the provenance of a verdict.
+type VerdictSource int
 type SibylUpdateType string
 
 type sibylCore struct {
-	Token      string
-	HostUrl    string
-	Context    context.Context
-	HttpClient *http.Client
+	Token       string
+	HostUrl     string
+	Context     context.Context
+	HttpClient  *http.Client
+	RateLimiter RateLimiter
+
+	infoCache *infoCache
+
+	infoFlight        *flightGroup[flightKey, *GetInfoResult]
+	generalInfoFlight *flightGroup[flightKey, *GeneralInfoResult]
+	statsFlight       *flightGroup[flightKey, *GetStatsResult]
+	tokenFlight       *flightGroup[flightKey, *TokenInfo]
+}
+
+// flightKey is the key used for deduplicating identical read requests;
+// the token is a part of the key, since the same request sent with
+// different tokens might get different results from the server.
+type flightKey struct {
+	token  string
+	userId int64
+}
+
+// flightCall represents a single in-flight request which might be
+// shared between multiple callers.
+type flightCall[T any] struct {
+	done    chan struct{}
+	value   T
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// flightGroup collapses concurrent calls with the same key into a single
+// call, sharing its result between all of the callers.
+type flightGroup[TKey comparable, TValue any] struct {
+	mut   *sync.Mutex
+	calls map[TKey]*flightCall[TValue]
 }
 
 type SibylConfig struct {
 	HostUrl    string
 	HttpClient *http.Client
 	Context    context.Context
+
+	// RateLimiter, if set, is waited on before sending each request
+	// to the server.
+	RateLimiter RateLimiter
+
+	// InfoCacheTTL is the duration which results of GetInfo are cached
+	// for; zero means GetInfo results are not cached at all.
+	InfoCacheTTL time.Duration
+}
+
+// RateLimiter limits the rate of the requests sent to the server.
+// *rate.Limiter from golang.org/x/time/rate satisfies this interface.
+type RateLimiter interface {
+	// Wait blocks until the next request is allowed to be sent, or
+	// returns an error if the context is done before that.
+	Wait(ctx context.Context) error
+}
+
+// infoCache keeps the results of GetInfo for a limited duration.
+type infoCache struct {
+	mut       *sync.RWMutex
+	ttl       time.Duration
+	values    map[int64]*cachedInfo
+	lastSweep time.Time
+
+	// invalidated keeps the time the users have been invalidated at, so
+	// the results of the requests sent before that aren't cached.
+	invalidated map[int64]time.Time
+}
+
+type cachedInfo struct {
+	info     *GetInfoResult
+	cachedAt time.Time
+}
+
+type GetInfoManyOptions struct {
+	// Concurrency is the maximum count of requests being sent at the
+	// same time; if zero, DefaultBulkConcurrency will be used.
+	Concurrency int
+
+	// OnProgress, if set, is called after each user-id is resolved
+	// (successfully or not). calls are never made concurrently.
+	OnProgress func(progress *BulkProgress)
+}
+
+// BulkProgress represents the progress of a bulk operation.
+type BulkProgress struct {
+	UserId    int64
+	Done      int
+	Total     int
+	Err       error
+	FromCache bool
+}
+
+type BulkOptions struct {
+	// Concurrency is the maximum count of requests being sent at the
+	// same time; if zero, DefaultBulkConcurrency will be used.
+	Concurrency int
+
+	// OnProgress, if set, is called after each target is done (successfully
+	// or not). calls are never made concurrently.
+	OnProgress func(progress *BulkProgress)
+
+	// CheckpointPath, if set, is the path of a file which the progress of
+	// the operation is saved to after each successful target. running the
+	// same operation with the same checkpoint path again will skip the
+	// targets which have already been done.
+	CheckpointPath string
+}
+
+// BanTarget is a single target of BanMany.
+type BanTarget struct {
+	UserId int64
+	Reason string
+
+	// Flags are appended to the reason as hashtags, since sibyl
+	// derives the flags of a ban from its reason.
+	Flags  []BanFlag
+	Config *BanConfig
+}
+
+// RevertTarget is a single target of RemoveBanMany.
+type RevertTarget struct {
+	UserId int64
+	Reason string
+	Config *RevertConfig
+}
+
+// BanOutcome is the outcome of banning a single target. Skipped is true
+// if the target has already been banned in a previous run of the same
+// operation (according to the checkpoint).
+type BanOutcome struct {
+	UserId  int64
+	Result  *BanResult
+	Err     error
+	Skipped bool
+
+	// CheckpointErr is the error of saving the checkpoint after the
+	// target has been banned successfully; the ban itself isn't affected
+	// by it, but the target will be done again if the operation is resumed.
+	CheckpointErr error
+}
+
+// RevertOutcome is the outcome of removing the ban of a single target.
+type RevertOutcome struct {
+	UserId  int64
+	Result  string
+	Err     error
+	Skipped bool
+
+	// CheckpointErr is the error of saving the checkpoint after the ban
+	// of the target has been removed successfully.
+	CheckpointErr error
+}
+
+// BanManyReport contains the outcome of each target of BanMany, in the
+// same order as the targets; it never contains nil outcomes. nil targets
+// fail with ErrNilTarget, repeated user-ids fail with ErrDuplicateTarget
+// (only the first target of each user is done) and the targets which
+// haven't been done because of cancellation fail with the error of the
+// context.
+type BanManyReport struct {
+	Outcomes       []*BanOutcome
+	SucceededCount int
+	FailedCount    int
+	SkippedCount   int
+}
+
+// RemoveBanManyReport contains the outcome of each target of RemoveBanMany,
+// in the same order as the targets; it's filled the same way as
+// BanManyReport.
+type RemoveBanManyReport struct {
+	Outcomes       []*RevertOutcome
+	SucceededCount int
+	FailedCount    int
+	SkippedCount   int
+}
+
+// bulkOutcome is the outcome of a single target of runBulk.
+type bulkOutcome[T any] struct {
+	userId        int64
+	result        T
+	err           error
+	skipped       bool
+	checkpointErr error
+}
+
+// bulkCheckpoint keeps the successfully done targets of a bulk operation.
+type bulkCheckpoint[T any] struct {
+	mut       *sync.Mutex
+	path      string
+	Operation string      `json:"operation"`
+	Completed map[int64]T `json:"completed"`
+}
+
+// GetInfoManyResult contains the results of GetInfoMany; each one of the
+// requested user-ids is either in Results or in Errors, unless the
+// operation got cancelled.
+type GetInfoManyResult struct {
+	Results     map[int64]*GetInfoResult
+	Errors      map[int64]error
+	CachedCount int
+}
+
+// BanChecker decides whether users are banned, using the cache of the
+// client, live lookups and a local copy of the ban list, and degrades
+// gracefully when the server is unreachable.
+type BanChecker struct {
+	client        SibylClient
+	mirror        BanSource
+	policy        DegradationPolicy
+	staleFallback DegradationPolicy
+	maxStaleness  time.Duration
+
+	// lastKnown keeps the results of the live lookups for maxStaleness,
+	// only used with DegradeUseStale.
+	lastKnown *infoCache
+}
+
+type BanCheckerConfig struct {
+	// Policy decides the verdict when the live lookup fails. the default
+	// is DegradeFailOpen.
+	Policy DegradationPolicy
+
+	// MaxStaleness is the max age of the data used with DegradeUseStale.
+	// if zero, DefaultMaxStaleness is used.
+	MaxStaleness time.Duration
+
+	// StaleFallback is the policy used with DegradeUseStale when there is
+	// no data fresh enough; it's either DegradeFailOpen or
+	// DegradeFailClosed. the default is DegradeFailOpen.
+	StaleFallback DegradationPolicy
+
+	// Mirror is the local copy of the ban list used with DegradeUseStale,
+	// e.g. the Mirror of the banMirror package. it's optional.
+	Mirror BanSource
+}
+
+// BanSource is a local copy of the ban list.
+type BanSource interface {
+	// GetBan returns the ban of the user, or nil if the user isn't banned.
+	GetBan(userId int64) *BanInfo
+
+	// GetSyncedAt returns the time the ban list was fetched at; zero means
+	// it has never been fetched.
+	GetSyncedAt() time.Time
+}
+
+// Verdict is the answer of BanChecker to whether a user is banned.
+type Verdict struct {
+	UserId int64
+	Banned bool
+	Source VerdictSource
+
+	// Info is the info of the user; it's set for VerdictSourceLive and
+	// VerdictSourceCache.
+	Info *GetInfoResult
+
+	// Ban is the ban of the user in the mirror; it's only set for
+	// VerdictSourceMirror if the user is banned.
+	Ban *BanInfo
+
+	// FetchedAt is the time the data of the verdict was fetched at; it's
+	// zero for VerdictSourceDefault.
+	FetchedAt time.Time
+
+	// Err is the error of the live lookup; if it's not nil, the verdict
+	// has been decided by the degradation policy.
+	Err error
 }
 
 type SibylDispatcher struct {
-	PollingId          *PollingIdentifier
-	TimeoutSeconds     int
+	PollingId      *PollingIdentifier
+	TimeoutSeconds int
+
+	// MaxConnectionTries is the max count of the consecutive failed
+	// attempts before the dispatcher gives up; zero or negative means
+	// retrying forever.
 	MaxConnectionTries int
-	isStopped          bool
-	sibylClient        SibylClient
-	totalTries         int
+
+	// Workers is the count of the goroutines running the handlers. if not
+	// positive, DefaultDispatcherWorkers is used.
+	Workers int
+
+	// QueueSize is the count of the updates which can wait for a worker;
+	// when the queue is full, getting new updates is paused. if not
+	// positive, DefaultDispatcherQueueSize is used.
+	QueueSize int
+
+	// Backoff decides the delays between the retries when the server can't
+	// be reached or returns errors. if nil, the default ExponentialBackoff
+	// is used.
+	Backoff BackoffPolicy
+
+	// OrderByTarget makes the updates of the same target user to be
+	// handled sequentially, in the order they have been received.
+	OrderByTarget bool
+
+	sibylClient SibylClient
+
+	// mut protects the state of the running loop.
+	mut      *sync.Mutex
+	running  bool
+	cancel   context.CancelFunc
+	doneChan chan struct{}
+
+	// lastErr is the error which the last run of the loop stopped with.
+	lastErr error
+
+	// workersWg tracks the running workers, so they can be waited for
+	// before the loop returns.
+	workersWg *sync.WaitGroup
 
 	onStartFailed     func(error)
 	onGetUpdateFailed func(error)
 	onHandlerError    func(error)
+	onConnected       func(*PollingIdentifier)
+	onDisconnected    func(error)
+	onGiveUp          func(error)
 
 	handlers *ssg.SafeMap[SibylUpdateType, []ServerUpdateHandler]
 }
@@ -65,6 +387,10 @@ type SibylClient interface {
 	// Ban bans user with given id, reason and BanConfig.
 	Ban(userId int64, reason string, config *BanConfig) (*BanResult, error)
 
+	// BanWithContext is the same as Ban, but the request can be cancelled
+	// using the given context.
+	BanWithContext(ctx context.Context, userId int64, reason string, config *BanConfig) (*BanResult, error)
+
 	// BanUser bans a "user" with given id, reason and BanConfig.
 	// entityType will be set to "user".
 	BanUser(userId int64, reason string, config *BanConfig) (*BanResult, error)
@@ -76,9 +402,23 @@ type SibylClient interface {
 	// RemoveBan removes ban from user with given id.
 	RemoveBan(userId int64, reason string, config *RevertConfig) (string, error)
 
+	// RemoveBanWithContext is the same as RemoveBan, but the request can be
+	// cancelled using the given context.
+	RemoveBanWithContext(ctx context.Context, userId int64, reason string, config *RevertConfig) (string, error)
+
 	// RevertBan reverts the ban from user with given id.
 	RevertBan(userId int64, reason string, config *RevertConfig) (string, error)
 
+	// BanMany bans all of the given targets, sending the requests concurrently.
+	// errors of each target are collected in the report; the returned error is
+	// only non-nil if the checkpoint can't be loaded, or if the context is done
+	// before all targets are done (the partial report is returned as well).
+	BanMany(ctx context.Context, targets []*BanTarget, opts *BulkOptions) (*BanManyReport, error)
+
+	// RemoveBanMany removes the bans of all of the given targets, sending the
+	// requests concurrently. it works the same way as BanMany.
+	RemoveBanMany(ctx context.Context, targets []*RevertTarget, opts *BulkOptions) (*RemoveBanManyReport, error)
+
 	// FullRevert will fully revert the target user, they won't get `Restored` status,
 	// all of their bans history will be deleted.
 	// This method requires high token permission.
@@ -87,16 +427,52 @@ type SibylClient interface {
 	// GetInfo returns information about the user with given id.
 	GetInfo(userId int64) (*GetInfoResult, error)
 
+	// GetInfoWithContext is the same as GetInfo, but the wait for the result
+	// can be cancelled using the given context. concurrent calls for the same
+	// user-id are collapsed into a single request.
+	GetInfoWithContext(ctx context.Context, userId int64) (*GetInfoResult, error)
+
+	// GetInfoMany returns information about all of the users with given ids,
+	// sending the requests concurrently. ids found in the cache are resolved
+	// immediately. errors of each id are collected in the result; the returned
+	// error is only non-nil if the context is done before all ids are resolved,
+	// in that case the partial result is returned as well.
+	GetInfoMany(ctx context.Context, ids []int64, opts *GetInfoManyOptions) (*GetInfoManyResult, error)
+
+	// ClearInfoCache removes all of the cached GetInfo results.
+	ClearInfoCache()
+
+	// GetCachedInfo returns the cached GetInfo result of the user and the
+	// time it was cached at, without sending any request; it returns nil
+	// if the user isn't cached.
+	GetCachedInfo(userId int64) (*GetInfoResult, time.Time)
+
 	// GetGeneralInfo returns information about the user with given id.
 	// if the user is not a registered user at PSB, server will return error.
 	GetGeneralInfo(userId int64) (*GeneralInfoResult, error)
 
+	// GetGeneralInfoWithContext is the same as GetGeneralInfo, but the wait for
+	// the result can be cancelled using the given context. concurrent calls for
+	// the same user-id are collapsed into a single request.
+	GetGeneralInfoWithContext(ctx context.Context, userId int64) (*GeneralInfoResult, error)
+
 	// GetGetAllBannedUsers returns information about all banned users.
 	GetGetAllBannedUsers() (*GetBansResult, error)
 
+	// StreamBannedUsers fetches all of the banned users and calls fn for each one
+	// of them while the response is being decoded, so the whole ban list is never
+	// held in the memory. the info passed to fn is only valid during the call,
+	// returning false from fn stops the streaming without any error.
+	StreamBannedUsers(ctx context.Context, fn func(info *BanInfo) bool) error
+
 	// GetStats returns current server stats.
 	GetStats() (*GetStatsResult, error)
 
+	// GetStatsWithContext is the same as GetStats, but the wait for the result
+	// can be cancelled using the given context. concurrent calls are collapsed
+	// into a single request.
+	GetStatsWithContext(ctx context.Context) (*GetStatsResult, error)
+
 	// CheckToken checks if the token is valid.
 	CheckToken() (bool, error)
 
@@ -132,6 +508,11 @@ type SibylClient interface {
 	// it needs owner permission if the user-id doesn't belong to yourself.
 	GetToken(userId int64) (*TokenInfo, error)
 
+	// GetTokenWithContext is the same as GetToken, but the wait for the result
+	// can be cancelled using the given context. concurrent calls for the same
+	// user-id are collapsed into a single request.
+	GetTokenWithContext(ctx context.Context, userId int64) (*TokenInfo, error)
+
 	// GetAllRegisteredUsers returns information about all registered users.
 	GetAllRegisteredUsers() (*GetRegisteredResult, error)
 
@@ -148,6 +529,14 @@ type SibylClient interface {
 	// second arg (second arg is not mandatory, and can be set to 0).
 	GetUpdates(timeout int, uniqueId *PollingIdentifier) (*ServerUpdateContainer, error)
 
+	// StartPollingWithContext is the same as StartPolling, but the request
+	// can be cancelled using the given context.
+	StartPollingWithContext(ctx context.Context) (*PollingIdentifier, error)
+
+	// GetUpdatesWithContext is the same as GetUpdates, but the long-poll
+	// request can be cancelled using the given context.
+	GetUpdatesWithContext(ctx context.Context, timeout int, uniqueId *PollingIdentifier) (*ServerUpdateContainer, error)
+
 	// String returns string representation of the current SibylClient.
 	String() string
 
@@ -158,11 +547,102 @@ type SibylClient interface {
 	Print()
 }
 
+// SibylTime is a time sent by the sibyl's servers. it accepts all of the
+// layouts in SibylTimeLayouts (and unix timestamps), and keeps the original
+// value, so it's marshalled back exactly the same way it was received
+// (as long as the time isn't changed).
+type SibylTime struct {
+	time.Time
+
+	// raw is the original value, and parsed is the time it was parsed as;
+	// raw is only used while Time is still the same as parsed.
+	raw    []byte
+	parsed time.Time
+}
+
+// BanFlagInfo contains the metadata of a ban flag.
+type BanFlagInfo struct {
+	Flag        BanFlag
+	Description string
+	Severity    BanSeverity
+}
+
+// banFlagRegistry keeps all of the known ban flags; flags which are not
+// registered are considered as invalid.
+type banFlagRegistry struct {
+	mut    *sync.RWMutex
+	flags  map[BanFlag]*BanFlagInfo
+	order  []BanFlag
+	strict int32
+}
+
+// Formatter formats text for a parse mode of telegram; all of the
+// formatted helpers accept a Formatter, so they can be used with
+// either of MarkdownV2 or HTML parse modes.
+type Formatter interface {
+	// GetParseMode returns the telegram parse mode of the formatter.
+	GetParseMode() string
+
+	// Escape escapes the raw text, so it's shown as it is.
+	Escape(text string) string
+
+	// Style applies the style to the text; the text should be
+	// already escaped (or formatted).
+	Style(style TextStyle, text string) string
+
+	// Link returns a link to the url with the given text; the text should
+	// be already escaped (or formatted), but the url shouldn't.
+	Link(text, url string) string
+}
+
+// MarkdownFormatter formats text for the MarkdownV2 parse mode.
+type MarkdownFormatter struct{}
+
+// HTMLFormatter formats text for the HTML parse mode.
+type HTMLFormatter struct{}
+
+// PlainFormatter doesn't format the text at all; links are written as
+// the text followed by the url.
+type PlainFormatter struct{}
+
+// messageCatalog wraps a catalog.Builder; it keeps track of the keys of
+// each language, so missing translations fall back to english per key.
+type messageCatalog struct {
+	mut     *sync.RWMutex
+	builder *catalog.Builder
+	keys    map[language.Tag]map[string]bool
+	tags    []language.Tag
+	matcher language.Matcher
+}
+
+// ReasonBuilder composes a ban reason from typed flags and free text,
+// so the flags are always written as hashtags sibyl can understand.
+type ReasonBuilder struct {
+	flags []BanFlag
+	texts []string
+	perma bool
+}
+
+// ParsedReason is the result of parsing a ban reason.
+type ParsedReason struct {
+	// Raw is the original reason.
+	Raw string
+	// Flags are the known flags found in the reason as hashtags,
+	// without duplicates and in the order they appeared.
+	Flags []BanFlag
+	// UnknownTags are the hashtags which aren't known ban flags.
+	UnknownTags []string
+	// Text is the reason without the hashtags of the known flags.
+	Text string
+	// Perma is true if the reason marks the ban as permanent.
+	Perma bool
+}
+
 type SibylError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Origin  string `json:"origin"`
-	Date    string `json:"date"`
+	Code    int       `json:"code"`
+	Message string    `json:"message"`
+	Origin  string    `json:"origin"`
+	Date    SibylTime `json:"date"`
 }
 
 type CymaticScanConfig struct {
@@ -199,16 +679,16 @@ type AddBanResponse struct {
 }
 
 type BanInfo struct {
-	UserId           int64      `json:"user_id"`
-	Banned           bool       `json:"banned"`
-	Reason           string     `json:"reason"`
-	Message          string     `json:"message"`
-	BanSourceUrl     string     `json:"ban_source_url"`
-	BannedBy         int64      `json:"banned_by"`
-	CrimeCoefficient int64      `json:"crime_coefficient"`
-	Date             string     `json:"date"`
-	BanFlags         []string   `json:"ban_flags"`
-	TargetType       EntityType `json:"target_type"`
+	UserId           int64            `json:"user_id"`
+	Banned           bool             `json:"banned"`
+	Reason           string           `json:"reason"`
+	Message          string           `json:"message"`
+	BanSourceUrl     string           `json:"ban_source_url"`
+	BannedBy         int64            `json:"banned_by"`
+	CrimeCoefficient CrimeCoefficient `json:"crime_coefficient"`
+	Date             SibylTime        `json:"date"`
+	BanFlags         []BanFlag        `json:"ban_flags"`
+	TargetType       EntityType       `json:"target_type"`
 }
 
 // Remove ban types:
@@ -234,16 +714,16 @@ type GetInfoResponse struct {
 }
 
 type GetInfoResult struct {
-	UserId           int64      `json:"user_id"`
-	Banned           bool       `json:"banned"`
-	Reason           string     `json:"reason"`
-	Message          string     `json:"message"`
-	BanSourceUrl     string     `json:"ban_source_url"`
-	BannedBy         int64      `json:"banned_by"`
-	CrimeCoefficient int        `json:"crime_coefficient"`
-	Date             string     `json:"date"`
-	BanFlags         []BanFlag  `json:"ban_flags"`
-	TargetType       EntityType `json:"target_type"`
+	UserId           int64            `json:"user_id"`
+	Banned           bool             `json:"banned"`
+	Reason           string           `json:"reason"`
+	Message          string           `json:"message"`
+	BanSourceUrl     string           `json:"ban_source_url"`
+	BannedBy         int64            `json:"banned_by"`
+	CrimeCoefficient CrimeCoefficient `json:"crime_coefficient"`
+	Date             SibylTime        `json:"date"`
+	BanFlags         []BanFlag        `json:"ban_flags"`
+	TargetType       EntityType       `json:"target_type"`
 }
 
 // general info types
@@ -259,7 +739,7 @@ type GeneralInfoResult struct {
 	Division       int            `json:"division"`
 	AssignedBy     int64          `json:"assigned_by"`
 	AssignedReason string         `json:"assigned_reason"`
-	AssignedAt     string         `json:"assigned_at"`
+	AssignedAt     SibylTime      `json:"assigned_at"`
 	Permission     UserPermission `json:"permission"`
 }
 
@@ -329,7 +809,7 @@ type TokenInfo struct {
 	UserId          int64          `json:"user_id" gorm:"primaryKey"`
 	Hash            string         `json:"hash"`
 	Permission      UserPermission `json:"permission"`
-	CreatedAt       string         `json:"created_at"`
+	CreatedAt       SibylTime      `json:"created_at"`
 	AcceptedReports int            `json:"accepted_reports"`
 	DeniedReports   int            `json:"denied_reports"`
 	AssignedBy      int64          `json:"assigned_by"`
@@ -419,8 +899,87 @@ type ScanRequestRejectedUpdate struct {
 }
 
 type SibylUpdateContext struct {
+	UpdateType SibylUpdateType
+
+	// Data is the raw data of the update.
+	Data json.RawMessage
+
+	// Value is the update decoded by the decoder of its type; it's nil if
+	// the type has no decoder.
+	Value any
+
 	ScanRequestApproved *ScanRequestApprovedUpdate
-	ScanRequestRejected *ScanRequestApprovedUpdate
+	ScanRequestRejected *ScanRequestRejectedUpdate
+}
+
+// UpdateDecoder decodes the data of an update; the returned value is set
+// in SibylUpdateContext.Value.
+type UpdateDecoder func(data json.RawMessage) (any, error)
+
+// TargetedUpdate is an update which is about a specific user; the updates
+// implementing it can be ordered by SibylDispatcher.OrderByTarget.
+type TargetedUpdate interface {
+	GetTargetUser() int64
+}
+
+// updateDecoderRegistry keeps the decoders of the known update types.
+type updateDecoderRegistry struct {
+	mut      *sync.RWMutex
+	decoders map[SibylUpdateType]UpdateDecoder
+}
+
+// BackoffPolicy decides the delays between the retries of a failing
+// operation.
+type BackoffPolicy interface {
+	// NextDelay returns the delay before the given attempt (starting from
+	// 1), elapsed is the time passed since the first failure. it returns
+	// false if no more attempts should be made.
+	NextDelay(attempt int, elapsed time.Duration) (time.Duration, bool)
+}
+
+// ExponentialBackoff multiplies the delay after each failed attempt, with
+// a random jitter. the zero value of each field uses its default.
+type ExponentialBackoff struct {
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	Multiplier   float64
+
+	// Jitter is the ratio of the random change of each delay, between
+	// 0 and 1; e.g. 0.2 makes a 10s delay to be between 8s and 12s.
+	Jitter float64
+
+	// MaxElapsedTime is the time after the first failure in which the
+	// retries are made; zero means retrying forever.
+	MaxElapsedTime time.Duration
+}
+
+// ConstantBackoff waits the same delay before each retry.
+type ConstantBackoff struct {
+	Delay time.Duration
+
+	// MaxElapsedTime is the time after the first failure in which the
+	// retries are made; zero means retrying forever.
+	MaxElapsedTime time.Duration
+}
+
+// GiveUpError is the error returned by the dispatcher when it gives up
+// connecting to the server.
+type GiveUpError struct {
+	Attempts int
+	Elapsed  time.Duration
+	Err      error
+}
+
+// retryState is the state of the consecutive failures of the dispatcher.
+type retryState struct {
+	attempts int
+	since    time.Time
+}
+
+// dispatchJob is an update waiting in the queue of the dispatcher.
+type dispatchJob struct {
+	updateType SibylUpdateType
+	ctx        *SibylUpdateContext
 }
 
 type ServerUpdateHandler func(client SibylClient, ctx *SibylUpdateContext) error
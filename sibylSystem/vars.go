@@ -5,11 +5,171 @@
 
 package sibylSystem
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 // error variables
 var (
 	ErrInvalidHostUrl = errors.New("invalid host url")
 	ErrInvalidToken   = errors.New("token length should be more than 20")
 	ErrNoReason       = errors.New("reason is required for this action")
+	ErrInvalidResp    = errors.New("server returned an invalid response")
+
+	ErrCheckpointMismatch = errors.New("checkpoint file belongs to another kind of bulk operation")
+	ErrNilTarget          = errors.New("bulk target is nil")
+	ErrInvalidEntityType  = errors.New("invalid entity type")
+	ErrDuplicateTarget    = errors.New("user is already a target of this bulk operation")
+	ErrInvalidTime        = errors.New("time doesn't match any of the known layouts")
+	ErrUnknownBanFlag     = errors.New("unknown ban flag")
+	ErrBanFlagExists      = errors.New("ban flag is already registered")
+	ErrUnknownReasonFlag  = errors.New("reason contains a hashtag which isn't a known ban flag")
+	ErrUnknownMessageKey  = errors.New("unknown message key")
+	ErrNoClient           = errors.New("a sibyl client is required")
+	ErrDispatcherRunning  = errors.New("dispatcher is already running")
+	ErrUpdateTypeExists   = errors.New("update type already has a decoder")
+	ErrUnexpectedUpdate   = errors.New("update value doesn't match the type of the handler")
+
+	// errStopStreaming is used internally for stopping a streaming
+	// decode when the callback returns false.
+	errStopStreaming = errors.New("streaming stopped by the callback")
+)
+
+// banFlags is the registry of the known ban flags; custom flags can be
+// added to it using RegisterBanFlag.
+var banFlags = newBanFlagRegistry(
+	&BanFlagInfo{BanFlagTrolling, "Trolling or flaming other users", SeverityLow},
+	&BanFlagInfo{BanFlagSpam, "Spamming messages or links", SeverityMedium},
+	&BanFlagInfo{BanFlagEvade, "Evading a previous ban", SeverityHigh},
+	&BanFlagInfo{BanFlagCustom, "Custom reason, see the reason of the ban", SeverityMedium},
+	&BanFlagInfo{BanFlagPsychoHazard, "Mass banning or kicking the members of a group", SeverityCritical},
+	&BanFlagInfo{BanFlagMalImp, "Malicious impersonation", SeverityHigh},
+	&BanFlagInfo{BanFlagNSFW, "Sending NSFW content", SeverityMedium},
+	&BanFlagInfo{BanFlagRaid, "Raiding groups", SeverityHigh},
+	&BanFlagInfo{BanFlagSpamBot, "Spam bot account", SeverityHigh},
+	&BanFlagInfo{BanFlagMassAdd, "Mass adding members to groups", SeverityHigh},
+)
+
+// updateDecoders is the registry of the decoders of the update types;
+// new types can be added to it using RegisterUpdateDecoder.
+var updateDecoders = newUpdateDecoderRegistry(map[SibylUpdateType]UpdateDecoder{
+	UpdateTypeScanRequestApproved: JsonUpdateDecoder[ScanRequestApprovedUpdate](),
+	UpdateTypeScanRequestRejected: JsonUpdateDecoder[ScanRequestRejectedUpdate](),
+})
+
+// markdownStyles and htmlStyles contain the prefix and suffix of
+// each style for their formatter.
+var (
+	markdownStyles = map[TextStyle][2]string{
+		StyleBold:      {"*", "*"},
+		StyleItalic:    {"_", "_"},
+		StyleUnderline: {"__", "__"},
+		StyleStrike:    {"~", "~"},
+		StyleSpoiler:   {"||", "||"},
+		StyleMono:      {"`", "`"},
+	}
+	htmlStyles = map[TextStyle][2]string{
+		StyleBold:      {"<b>", "</b>"},
+		StyleItalic:    {"<i>", "</i>"},
+		StyleUnderline: {"<u>", "</u>"},
+		StyleStrike:    {"<s>", "</s>"},
+		StyleSpoiler:   {"<tg-spoiler>", "</tg-spoiler>"},
+		StyleMono:      {"<code>", "</code>"},
+	}
 )
+
+// englishMessages are the messages of the default catalog; other locales
+// can translate any of them using RegisterLocale or RegisterMessage.
+var englishMessages = map[string]string{
+	MsgKeyYes:                  "Yes",
+	MsgKeyNo:                   "No",
+	MsgKeyListSeparator:        ", ",
+	MsgKeyListLastSeparator:    " and ",
+	MsgKeyEstimateUnder:        "under ",
+	MsgKeyEstimateOver:         "over ",
+	MsgKeyEstimateExactly:      "exactly ",
+	MsgKeyJustNow:              "just now",
+	MsgKeyTimeAgo:              "%s ago",
+	MsgKeyTimeIn:               "in %s",
+	MsgKeyBandNormal:           "Normal",
+	MsgKeyBandLatentCriminal:   "Latent criminal",
+	MsgKeyBandParalyzer:        "Paralyzer",
+	MsgKeyBandLethalEliminator: "Lethal eliminator",
+	MsgKeyBandUnknown:          "Unknown",
+	MsgKeyPermNormalUser:       "Normal user",
+	MsgKeyPermEnforcer:         "Enforcer",
+	MsgKeyPermInspector:        "Inspector",
+	MsgKeyPermOwner:            "Owner",
+	MsgKeyEntityUser:           "User",
+	MsgKeyEntityBot:            "Bot",
+	MsgKeyEntityAdmin:          "Admin",
+	MsgKeyEntityOwner:          "Owner",
+	MsgKeyEntityChannel:        "Channel",
+	MsgKeyEntityGroup:          "Group",
+	MsgKeyUnknown:              "Unknown",
+}
+
+// englishUnits are the plural forms of the time units in english.
+var englishUnits = map[string][2]string{
+	MsgKeyUnitSecond: {"%d second", "%d seconds"},
+	MsgKeyUnitMinute: {"%d minute", "%d minutes"},
+	MsgKeyUnitHour:   {"%d hour", "%d hours"},
+	MsgKeyUnitDay:    {"%d day", "%d days"},
+	MsgKeyUnitWeek:   {"%d week", "%d weeks"},
+	MsgKeyUnitMonth:  {"%d month", "%d months"},
+	MsgKeyUnitYear:   {"%d year", "%d years"},
+}
+
+// crimeBandKeys maps each crime band to the key of its label.
+var crimeBandKeys = map[CrimeBand]string{
+	CrimeBandNormal:           MsgKeyBandNormal,
+	CrimeBandLatentCriminal:   MsgKeyBandLatentCriminal,
+	CrimeBandParalyzer:        MsgKeyBandParalyzer,
+	CrimeBandLethalEliminator: MsgKeyBandLethalEliminator,
+}
+
+// permissionKeys maps each permission to the key of its name.
+var permissionKeys = map[UserPermission]string{
+	NormalUser: MsgKeyPermNormalUser,
+	Enforcer:   MsgKeyPermEnforcer,
+	Inspector:  MsgKeyPermInspector,
+	Owner:      MsgKeyPermOwner,
+}
+
+// entityTypeKeys maps each entity type to the key of its name.
+var entityTypeKeys = map[EntityType]string{
+	EntityTypeUser:    MsgKeyEntityUser,
+	EntityTypeBot:     MsgKeyEntityBot,
+	EntityTypeAdmin:   MsgKeyEntityAdmin,
+	EntityTypeOwner:   MsgKeyEntityOwner,
+	EntityTypeChannel: MsgKeyEntityChannel,
+	EntityTypeGroup:   MsgKeyEntityGroup,
+}
+
+// entityTypeNames maps the names accepted by ParseEntityType to the
+// entity types.
+var entityTypeNames = map[string]EntityType{
+	"user":    EntityTypeUser,
+	"bot":     EntityTypeBot,
+	"admin":   EntityTypeAdmin,
+	"owner":   EntityTypeOwner,
+	"channel": EntityTypeChannel,
+	"group":   EntityTypeGroup,
+}
+
+// messages is the catalog of all human readable outputs of the library.
+var messages = newMessageCatalog()
+
+// SibylTimeLayouts are the layouts tried (in order) when parsing the times
+// sent by the server; more layouts can be appended if a server uses
+// another format.
+var SibylTimeLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 at 15:04:05",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
@@ -0,0 +1,259 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sibylSystemGo "github.com/ALiwoto/sibylSystemGo/sibylSystem"
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/banAllow"
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/sibylGuard"
+)
+
+func TestBanAllow01(t *testing.T) {
+	client, _ := newTestClient(t, getGuardTestRoutes(), nil)
+
+	allowlist, err := banAllow.NewAllowlist(nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	_, err = allowlist.Allow(-1001, 100, 42, "vetted by the admins", 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if _, err = allowlist.Allow(-1001, 200, 42, " ", 0); err != banAllow.ErrNoReason {
+		t.Errorf("expected ErrNoReason, got %v", err)
+		return
+	}
+
+	guard, err := sibylGuard.NewGuard(client, &sibylGuard.GuardConfig{
+		Allowlist:     allowlist,
+		FailurePolicy: sibylGuard.FailClosed,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	decision := guard.CheckJoin(context.Background(), -1001, 100, false)
+	if !decision.IsAllowed() || !decision.Allowlisted || decision.Info == nil {
+		t.Errorf("expected the allowlisted user to be allowed, got %s", decision.Action)
+		return
+	}
+
+	decision = guard.CheckJoin(context.Background(), -1002, 100, false)
+	if decision.Action != sibylGuard.ActionBan || decision.Allowlisted {
+		t.Errorf("expected ban in another chat, got %s", decision.Action)
+		return
+	}
+
+	// the status is recorded on the first check.
+	entry := allowlist.Get(-1001, 100)
+	if entry == nil || entry.Status == nil || !entry.Status.Banned || entry.AddedBy != 42 {
+		t.Errorf("unexpected entry: %+v", entry)
+		return
+	}
+
+	_, err = allowlist.Allow(banAllow.GlobalChatId, 300, 42, "temporary", time.Millisecond)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	if allowlist.IsAllowed(-1003, 300) {
+		t.Error("expected the entry to be expired")
+		return
+	}
+
+	expired, err := allowlist.PurgeExpired()
+	if err != nil || len(expired) != 1 || allowlist.Length() != 1 {
+		t.Errorf("unexpected expired entries: %v, %v", expired, err)
+	}
+}
+
+func TestBanAllow02(t *testing.T) {
+	var banned int32 = 1
+	client, _ := newTestClient(t, sibylRoutes{
+		"getInfo": func(w http.ResponseWriter, r *http.Request) {
+			if atomic.LoadInt32(&banned) == 1 {
+				_, _ = w.Write([]byte(`{"success":true,"result":{"user_id":100,"banned":true,` +
+					`"reason":"spam bot","crime_coefficient":150}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"success":true,"result":{"user_id":100,"banned":false}}`))
+		},
+	}, nil)
+
+	path := filepath.Join(t.TempDir(), "allowlist.json")
+	allowlist, err := banAllow.NewAllowlist(&banAllow.AllowlistConfig{
+		Store: banAllow.NewFileStore(path),
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	_, err = allowlist.Allow(banAllow.GlobalChatId, 100, 42, "known member", 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var notified int32
+	allowlist.SetOnStatusChange(func(change *banAllow.StatusChange) {
+		if change.IsUnbanned() {
+			atomic.AddInt32(&notified, 1)
+		}
+	})
+
+	report, err := allowlist.Reconcile(context.Background(), client)
+	if err != nil || report.Checked != 1 || report.HasChanges() {
+		t.Errorf("unexpected first report: %+v, %v", report, err)
+		return
+	}
+
+	atomic.StoreInt32(&banned, 0)
+	report, err = allowlist.Reconcile(context.Background(), client)
+	if err != nil || len(report.Changes) != 1 || !report.Changes[0].IsUnbanned() ||
+		atomic.LoadInt32(&notified) != 1 {
+		t.Errorf("unexpected second report: %+v, %v", report, err)
+		return
+	}
+
+	// the entries and their status are loaded from the file.
+	loaded, err := banAllow.NewAllowlist(&banAllow.AllowlistConfig{
+		Store: banAllow.NewFileStore(path),
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	entry := loaded.Get(-1005, 100)
+	if entry == nil || !entry.IsGlobal() || entry.Status == nil || entry.Status.Banned {
+		t.Errorf("unexpected loaded entry: %+v", entry)
+	}
+}
+
+func TestBanAllow03(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "allowlist.json")
+	allowlist, err := banAllow.NewAllowlist(&banAllow.AllowlistConfig{
+		Store: banAllow.NewFileStore(path),
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	_, err = allowlist.Allow(-1005, 100, 42, "known member", 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if allowlist.CheckAllowed(-1005, 200, &sibylSystemGo.GetInfoResult{UserId: 200, Banned: true}) {
+		t.Error("expected user 200 not to be allowed")
+		return
+	}
+
+	info := &sibylSystemGo.GetInfoResult{UserId: 100, Banned: true, Reason: "spam"}
+	if !allowlist.CheckAllowed(-1005, 100, info) {
+		t.Error("expected user 100 to be allowed")
+		return
+	}
+
+	// the status isn't saved on the hot path.
+	loaded, _ := banAllow.NewAllowlist(&banAllow.AllowlistConfig{Store: banAllow.NewFileStore(path)})
+	if entry := loaded.Get(-1005, 100); entry == nil || entry.Status != nil {
+		t.Errorf("expected the status not to be saved yet, got %+v", entry)
+		return
+	}
+
+	if err = allowlist.Flush(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	loaded, _ = banAllow.NewAllowlist(&banAllow.AllowlistConfig{Store: banAllow.NewFileStore(path)})
+	if entry := loaded.Get(-1005, 100); entry == nil || entry.Status == nil || !entry.Status.Banned {
+		t.Errorf("expected the status to be saved, got %+v", entry)
+	}
+}
+
+type failingAllowStore struct {
+	fail bool
+}
+
+func (s *failingAllowStore) Load() ([]*banAllow.Entry, error) {
+	return nil, nil
+}
+
+func (s *failingAllowStore) Save(entries []*banAllow.Entry) error {
+	if s.fail {
+		return errors.New("disk is full")
+	}
+
+	return nil
+}
+
+func TestBanAllow04(t *testing.T) {
+	store := &failingAllowStore{}
+	allowlist, err := banAllow.NewAllowlist(&banAllow.AllowlistConfig{Store: store})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	_, err = allowlist.Allow(-1005, 100, 42, "known member", 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	_, err = allowlist.Allow(-1005, 101, 42, "short visit", time.Millisecond)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	// nothing changes in memory if the store fails.
+	store.fail = true
+	if _, err = allowlist.Allow(-1005, 200, 42, "new member", 0); err == nil || allowlist.IsAllowed(-1005, 200) {
+		t.Errorf("expected the failed add to be rolled back, got %v", err)
+		return
+	}
+
+	if _, err = allowlist.Allow(-1005, 100, 7, "changed", 0); err == nil ||
+		allowlist.Get(-1005, 100).Reason != "known member" {
+		t.Errorf("expected the failed replace to be rolled back, got %v", err)
+		return
+	}
+
+	if removed, err := allowlist.Remove(-1005, 100); err == nil || removed || !allowlist.IsAllowed(-1005, 100) {
+		t.Errorf("expected the failed remove to be rolled back, got %v, %v", removed, err)
+		return
+	}
+
+	if expired, err := allowlist.PurgeExpired(); err == nil || expired != nil || allowlist.Length() != 2 {
+		t.Errorf("expected the failed purge to be rolled back, got %v, %v", expired, err)
+		return
+	}
+
+	// the observed status doesn't share the flags of the info.
+	info := &sibylSystemGo.GetInfoResult{UserId: 100, Banned: true, BanFlags: []sibylSystemGo.BanFlag{"SPAM"}}
+	allowlist.Observe(info)
+	info.BanFlags[0] = "TAMPERED"
+	if entry := allowlist.Get(-1005, 100); entry.Status == nil || entry.Status.BanFlags[0] != "SPAM" {
+		t.Errorf("unexpected status: %+v", entry.Status)
+	}
+}
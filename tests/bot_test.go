@@ -0,0 +1,221 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/sibylBot"
+)
+
+type fakeBotApiServer struct {
+	*httptest.Server
+	mut   *sync.Mutex
+	calls []string
+	texts []string
+}
+
+func newFakeBotApiServer() *fakeBotApiServer {
+	fake := &fakeBotApiServer{mut: &sync.Mutex{}}
+	fake.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+			// gotgbot sends the params as a json object of strings.
+			params := map[string]string{}
+			_ = json.NewDecoder(r.Body).Decode(&params)
+			for key, value := range params {
+				r.Form.Set(key, value)
+			}
+		}
+		method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+
+		fake.mut.Lock()
+		fake.calls = append(fake.calls, method+":"+r.Form.Get("user_id"))
+		if method == "sendMessage" {
+			fake.texts = append(fake.texts, r.Form.Get("text"))
+		}
+		fake.mut.Unlock()
+
+		if r.Form.Get("chat_id") == "0" {
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`))
+			return
+		}
+		if method == "sendMessage" {
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":` +
+				r.Form.Get("chat_id") + `,"type":"supergroup"}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+
+	return fake
+}
+
+func (f *fakeBotApiServer) get() ([]string, []string) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+
+	return append([]string(nil), f.calls...), append([]string(nil), f.texts...)
+}
+
+func getBotTestRoutes(reported *int64) sibylRoutes {
+	return sibylRoutes{
+		"reportUser": func(w http.ResponseWriter, r *http.Request) {
+			id, _ := strconv.ParseInt(r.URL.Query().Get("user-id"), 10, 64)
+			atomic.StoreInt64(reported, id)
+			_, _ = w.Write([]byte(`{"success":true,"result":"reported"}`))
+		},
+		"getInfo": func(w http.ResponseWriter, r *http.Request) {
+			switch r.Header.Get("user-id") {
+			case "100":
+				_, _ = w.Write([]byte(`{"success":true,"result":{"user_id":100,"banned":true,` +
+					`"reason":"spam","crime_coefficient":400,"ban_flags":["SPAM"]}}`))
+			default:
+				_, _ = w.Write([]byte(`{"success":true,"result":{"user_id":` +
+					r.Header.Get("user-id") + `,"banned":false}}`))
+			}
+		},
+	}
+}
+
+func TestBotAdapter01(t *testing.T) {
+	var reported int64
+	botServer := newFakeBotApiServer()
+	defer botServer.Close()
+	client, _ := newTestClient(t, getBotTestRoutes(&reported), nil)
+	bot := sibylBot.NewHttpBotApi("123:abc", &sibylBot.HttpBotApiConfig{
+		ApiUrl:     botServer.URL,
+		HttpClient: botServer.Client(),
+	})
+
+	adapter, err := sibylBot.NewAdapter(client, bot, &sibylBot.AdapterConfig{
+		Enforcers: []int64{7},
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	ctx := context.Background()
+	err = adapter.HandleMessage(ctx, &sibylBot.Message{
+		ChatId:     -1001,
+		MessageId:  1,
+		NewMembers: []sibylBot.Member{{UserId: 100}, {UserId: 200}},
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	calls, texts := botServer.get()
+	if len(calls) != 2 || calls[0] != "banChatMember:100" ||
+		!strings.HasPrefix(texts[0], "[100](tg://user?id=100) has been banned") {
+		t.Errorf("unexpected calls: %v, %v", calls, texts)
+		return
+	}
+
+	// a normal user can't report.
+	err = adapter.HandleMessage(ctx, &sibylBot.Message{
+		ChatId:    -1001,
+		MessageId: 2,
+		SenderId:  200,
+		Text:      "/scan@sibyl_bot spam",
+		ReplyTo:   &sibylBot.Message{SenderId: 300, Text: "buy crypto"},
+	})
+	_, texts = botServer.get()
+	if err != nil || atomic.LoadInt64(&reported) != 0 || texts[1] != "You are not allowed to use this command" {
+		t.Errorf("unexpected report: %d, %v, %v", reported, err, texts)
+		return
+	}
+
+	err = adapter.HandleMessage(ctx, &sibylBot.Message{
+		ChatId:    -1001,
+		MessageId: 3,
+		SenderId:  7,
+		Text:      "/report spam",
+		ReplyTo:   &sibylBot.Message{SenderId: 300, Text: "buy crypto"},
+	})
+	if err != nil || atomic.LoadInt64(&reported) != 300 {
+		t.Errorf("expected user 300 to be reported, got %d, %v", reported, err)
+		return
+	}
+
+	err = adapter.HandleMessage(ctx, &sibylBot.Message{
+		ChatId:    -1001,
+		MessageId: 4,
+		SenderId:  7,
+		Text:      "/sibylinfo 100",
+	})
+	_, texts = botServer.get()
+	last := texts[len(texts)-1]
+	if err != nil || !strings.Contains(last, "Crime coefficient: `400`") {
+		t.Errorf("unexpected info reply: %q, %v", last, err)
+		return
+	}
+
+	// /scan doesn't report, it checks the user and executes the decision.
+	atomic.StoreInt64(&reported, 0)
+	err = adapter.HandleMessage(ctx, &sibylBot.Message{
+		ChatId:    -1001,
+		MessageId: 5,
+		SenderId:  7,
+		Text:      "/scan",
+		ReplyTo:   &sibylBot.Message{SenderId: 100, Text: "buy crypto"},
+	})
+	calls, texts = botServer.get()
+	last = texts[len(texts)-1]
+	if err != nil || atomic.LoadInt64(&reported) != 0 ||
+		calls[len(calls)-2] != "banChatMember:100" ||
+		!strings.HasPrefix(last, "[100](tg://user?id=100) has been banned") {
+		t.Errorf("unexpected scan: %v, %q, %v", calls, last, err)
+		return
+	}
+
+	callCount := len(calls)
+	err = adapter.HandleMessage(ctx, &sibylBot.Message{
+		ChatId:    -1001,
+		MessageId: 6,
+		SenderId:  7,
+		Text:      "/scan 200",
+	})
+	calls, texts = botServer.get()
+	last = texts[len(texts)-1]
+	if err != nil || len(calls) != callCount+1 || !strings.Contains(last, "Banned: No") {
+		t.Errorf("unexpected scan of an allowed user: %v, %q, %v", calls, last, err)
+		return
+	}
+
+	err = adapter.HandleMessage(ctx, &sibylBot.Message{
+		ChatId:    -1001,
+		MessageId: 7,
+		SenderId:  7,
+		Text:      "/scan",
+	})
+	_, texts = botServer.get()
+	if err != nil || !strings.HasPrefix(texts[len(texts)-1], "Reply to a message of the user or pass their id: /scan") {
+		t.Errorf("unexpected scan usage: %v, %v", texts, err)
+	}
+}
+
+func TestBotAdapter02(t *testing.T) {
+	botServer := newFakeBotApiServer()
+	defer botServer.Close()
+
+	bot := sibylBot.NewHttpBotApi("123:abc", &sibylBot.HttpBotApiConfig{ApiUrl: botServer.URL})
+	err := bot.BanChatMember(context.Background(), 0, 100)
+	apiErr, ok := err.(*sibylBot.BotApiError)
+	if !ok || apiErr.Code != 400 || apiErr.Method != "banChatMember" {
+		t.Errorf("expected a bot api error, got %v", err)
+	}
+
+	name, args, ok := sibylBot.ParseCommand("/SibylInfo@bot  123 ")
+	if !ok || name != "sibylinfo" || args != "123" {
+		t.Errorf("unexpected command: %q %q %v", name, args, ok)
+	}
+}
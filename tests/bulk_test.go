@@ -0,0 +1,247 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sibylSystemGo "github.com/ALiwoto/sibylSystemGo/sibylSystem"
+)
+
+func TestGetInfoMany01(t *testing.T) {
+	var hits int32
+	client, _ := newTestClient(t, sibylRoutes{
+		"getInfo": func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&hits, 1)
+			if r.Header.Get("user-id") == "13" {
+				_, _ = w.Write([]byte(`{"success":false,"error":{"code":404,"message":"not found"}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"success":true,"result":{"user_id":` + r.Header.Get("user-id") + `}}`))
+		},
+	}, &sibylSystemGo.SibylConfig{
+		InfoCacheTTL: time.Minute,
+	})
+
+	if _, err := client.GetInfo(1); err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []int64
+	for i := int64(1); i <= 50; i++ {
+		ids = append(ids, i)
+	}
+
+	var progressCalls int
+	result, err := client.GetInfoMany(context.Background(), ids, &sibylSystemGo.GetInfoManyOptions{
+		Concurrency: 4,
+		OnProgress: func(progress *sibylSystemGo.BulkProgress) {
+			progressCalls++
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Results) != 49 || len(result.Errors) != 1 || result.Errors[13] == nil {
+		t.Errorf("unexpected result: %d results, %d errors", len(result.Results), len(result.Errors))
+	}
+
+	if result.CachedCount != 1 || hits != 50 {
+		t.Errorf("expected user 1 to be resolved from cache, got %d cached and %d hits",
+			result.CachedCount, hits)
+	}
+
+	if progressCalls != 50 {
+		t.Errorf("expected 50 progress calls, got %d", progressCalls)
+	}
+}
+
+func TestBanMany01(t *testing.T) {
+	var failing int32 = 1
+	var hits int32
+	client, _ := newTestClient(t, sibylRoutes{
+		"addBan": func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&hits, 1)
+			userId := r.URL.Query().Get("user-id")
+			if userId == "3" && atomic.LoadInt32(&failing) == 1 {
+				_, _ = w.Write([]byte(`{"success":false,"error":{"code":500,"message":"oops"}}`))
+				return
+			}
+
+			_, _ = w.Write([]byte(`{"success":true,"result":{"previous_ban":null,` +
+				`"current_ban":{"user_id":` + userId + `,"banned":true,"reason":"` +
+				r.URL.Query().Get("reason") + `"}}}`))
+		},
+	}, nil)
+
+	var targets []*sibylSystemGo.BanTarget
+	for i := int64(1); i <= 5; i++ {
+		targets = append(targets, &sibylSystemGo.BanTarget{
+			UserId: i,
+			Reason: "spam wave",
+			Flags:  []sibylSystemGo.BanFlag{sibylSystemGo.BanFlagSpamBot},
+		})
+	}
+
+	opts := &sibylSystemGo.BulkOptions{
+		CheckpointPath: filepath.Join(t.TempDir(), "ban-checkpoint.json"),
+	}
+
+	report, err := client.BanMany(context.Background(), targets, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.SucceededCount != 4 || report.FailedCount != 1 || report.Outcomes[2].Err == nil {
+		t.Fatalf("unexpected first report: %+v", report)
+	}
+
+	if reason := report.Outcomes[0].Result.CurrentBan.Reason; reason != "spam wave #SPAMBOT" {
+		t.Errorf("unexpected reason: %s", reason)
+	}
+
+	atomic.StoreInt32(&failing, 0)
+	atomic.StoreInt32(&hits, 0)
+
+	report, err = client.BanMany(context.Background(), targets, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.SucceededCount != 1 || report.SkippedCount != 4 || hits != 1 {
+		t.Errorf("expected only user 3 to be banned again, got %+v with %d hits", report, hits)
+	}
+}
+
+func TestBanInvalidatesCache01(t *testing.T) {
+	var banned int32
+	client, _ := newTestClient(t, sibylRoutes{
+		"addBan": func(w http.ResponseWriter, r *http.Request) {
+			atomic.StoreInt32(&banned, 1)
+			_, _ = w.Write([]byte(`{"success":true,"result":{"previous_ban":null,` +
+				`"current_ban":{"user_id":7,"banned":true,"reason":"spam"}}}`))
+		},
+		"getInfo": func(w http.ResponseWriter, r *http.Request) {
+			if atomic.LoadInt32(&banned) == 1 {
+				_, _ = w.Write([]byte(`{"success":true,"result":{"user_id":7,"banned":true,"reason":"spam"}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"success":true,"result":{"user_id":7,"banned":false}}`))
+		},
+	}, &sibylSystemGo.SibylConfig{
+		InfoCacheTTL: time.Minute,
+	})
+
+	info, err := client.GetInfo(7)
+	if err != nil || info.Banned {
+		t.Fatalf("unexpected info before the ban: %+v, %v", info, err)
+	}
+
+	if _, err = client.Ban(7, "spam", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if cached, _ := client.GetCachedInfo(7); cached != nil {
+		t.Fatal("expected the cached info to be removed after the ban")
+	}
+
+	info, err = client.GetInfo(7)
+	if err != nil || !info.Banned {
+		t.Errorf("expected the user to be banned, got %+v, %v", info, err)
+	}
+}
+
+func TestBanMany02(t *testing.T) {
+	var hits int32
+	client, _ := newTestClient(t, sibylRoutes{
+		"addBan": func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&hits, 1)
+			_, _ = w.Write([]byte(`{"success":true,"result":{"previous_ban":null,` +
+				`"current_ban":{"user_id":` + r.URL.Query().Get("user-id") + `,"banned":true}}}`))
+		},
+	}, nil)
+
+	targets := []*sibylSystemGo.BanTarget{
+		{UserId: 1, Reason: "spam"},
+		nil,
+		{UserId: 1, Reason: "spam again"},
+		{UserId: 2, Reason: "spam"},
+	}
+
+	// the directory of the checkpoint doesn't exist, so it can't be saved.
+	opts := &sibylSystemGo.BulkOptions{
+		CheckpointPath: filepath.Join(t.TempDir(), "missing", "checkpoint.json"),
+	}
+
+	report, err := client.BanMany(context.Background(), targets, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.SucceededCount != 2 || report.FailedCount != 2 || hits != 2 {
+		t.Fatalf("unexpected report: %+v with %d hits", report, hits)
+	}
+
+	if report.Outcomes[1].Err != sibylSystemGo.ErrNilTarget ||
+		report.Outcomes[2].Err != sibylSystemGo.ErrDuplicateTarget {
+		t.Errorf("unexpected errors: %v, %v", report.Outcomes[1].Err, report.Outcomes[2].Err)
+	}
+
+	if !report.Outcomes[0].IsSuccessful() || report.Outcomes[0].CheckpointErr == nil {
+		t.Errorf("expected a successful ban with a checkpoint error, got %+v", report.Outcomes[0])
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report, err = client.BanMany(ctx, targets[3:], nil)
+	if err != context.Canceled {
+		t.Fatalf("expected the context error, got %v", err)
+	}
+
+	if outcome := report.Outcomes[0]; outcome == nil || outcome.Err != context.Canceled ||
+		outcome.UserId != 2 || report.FailedCount != 1 {
+		t.Errorf("expected the unrun target to fail with the context error, got %+v", outcome)
+	}
+}
+
+func TestBanMany03(t *testing.T) {
+	started := make(chan struct{}, 2)
+	hold := func(w http.ResponseWriter, r *http.Request) {
+		// hold the request until the client gives up on it.
+		started <- struct{}{}
+		<-r.Context().Done()
+	}
+	client, _ := newTestClient(t, sibylRoutes{"addBan": hold, "remBan": hold}, nil)
+
+	// cancelling the context aborts the requests which are in flight.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	report, err := client.BanMany(ctx, []*sibylSystemGo.BanTarget{{UserId: 1, Reason: "spam"}}, nil)
+	if !errors.Is(err, context.Canceled) || !errors.Is(report.Outcomes[0].Err, context.Canceled) {
+		t.Errorf("expected the ban to be cancelled, got %v, %+v", err, report.Outcomes[0])
+		return
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	revertReport, err := client.RemoveBanMany(ctx, []*sibylSystemGo.RevertTarget{{UserId: 1, Reason: "mistake"}}, nil)
+	if !errors.Is(err, context.Canceled) || !errors.Is(revertReport.Outcomes[0].Err, context.Canceled) {
+		t.Errorf("expected the revert to be cancelled, got %v, %+v", err, revertReport.Outcomes[0])
+	}
+}
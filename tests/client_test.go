@@ -1,6 +1,22 @@
 package tests
 
-import "io/ioutil"
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	sibylSystemGo "github.com/ALiwoto/sibylSystemGo/sibylSystem"
+)
+
+// testToken is the token of the clients connected to the fake servers.
+const testToken = "test-token-which-is-long-enough"
+
+// sibylRoutes maps the methods of the sibyl api (e.g. "getInfo") to the
+// handlers of a fake sibyl server; the "" route handles the methods which
+// don't have a route of their own.
+type sibylRoutes map[string]http.HandlerFunc
 
 func getToken() string {
 	b, _ := ioutil.ReadFile("token.ini")
@@ -9,3 +25,31 @@ func getToken() string {
 	}
 	return string(b)
 }
+
+// newTestClient starts a fake sibyl server which serves the routes, and
+// returns a client connected to it; the server is closed at the end of
+// the test. config is optional; its HostUrl and HttpClient are replaced.
+func newTestClient(t *testing.T, routes sibylRoutes, config *sibylSystemGo.SibylConfig) (sibylSystemGo.SibylClient, *httptest.Server) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		handler := routes[method]
+		if handler == nil {
+			handler = routes[""]
+		}
+
+		if handler == nil {
+			http.NotFound(w, r)
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	if config == nil {
+		config = &sibylSystemGo.SibylConfig{}
+	}
+	config.HostUrl = server.URL
+	config.HttpClient = server.Client()
+
+	return sibylSystemGo.NewClient(testToken, config), server
+}
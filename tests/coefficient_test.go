@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"testing"
+
+	sibylSystemGo "github.com/ALiwoto/sibylSystemGo/sibylSystem"
+	"golang.org/x/text/language"
+)
+
+func TestCrimeCoefficient01(t *testing.T) {
+	estimates := map[sibylSystemGo.CrimeCoefficient]string{
+		0:    "under 100",
+		99:   "under 100",
+		100:  "exactly 100",
+		150:  "over 100",
+		199:  "over 100",
+		200:  "exactly 200",
+		1050: "over 1000",
+	}
+
+	for c, expected := range estimates {
+		if got := c.Estimate(); got != expected {
+			t.Errorf("estimate of %d: expected %q, got %q", c, expected, got)
+		}
+	}
+
+	bands := map[sibylSystemGo.CrimeCoefficient]sibylSystemGo.CrimeBand{
+		-5:  sibylSystemGo.CrimeBandNormal,
+		99:  sibylSystemGo.CrimeBandNormal,
+		100: sibylSystemGo.CrimeBandLatentCriminal,
+		250: sibylSystemGo.CrimeBandParalyzer,
+		300: sibylSystemGo.CrimeBandLethalEliminator,
+	}
+
+	for c, expected := range bands {
+		if got := c.GetBand(); got != expected {
+			t.Errorf("band of %d: expected %s, got %s", c, expected, got)
+		}
+	}
+
+	c := sibylSystemGo.CrimeCoefficient(320)
+	if c.GetAction() != sibylSystemGo.ActionBan || !c.IsTarget() {
+		t.Errorf("unexpected action for %d: %s", c, c.GetAction())
+	}
+}
+
+func TestCrimeCoefficient02(t *testing.T) {
+	band := sibylSystemGo.CrimeBandParalyzer
+	err := sibylSystemGo.SetCrimeBandLabelTag(language.Japanese, band, "パラライザー")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	ja, fa := band.GetLabelInTag(language.MustParse("ja-JP")), band.GetLabelInTag(language.Persian)
+	if ja != "パラライザー" || fa != "Paralyzer" {
+		t.Errorf("unexpected labels: %q, %q", ja, fa)
+	}
+}
+
+func TestCrimeCoefficient03(t *testing.T) {
+	band := sibylSystemGo.CrimeBandLethalEliminator
+	sibylSystemGo.SetCrimeBandLabel("ja", band, "エリミネーター")
+
+	if band.GetLabelIn("JA") != "エリミネーター" || band.GetLabelIn("fa") != "Lethal eliminator" {
+		t.Errorf("unexpected labels: %q, %q", band.GetLabelIn("ja"), band.GetLabelIn("fa"))
+	}
+}
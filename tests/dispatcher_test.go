@@ -0,0 +1,366 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sibylSystemGo "github.com/ALiwoto/sibylSystemGo/sibylSystem"
+)
+
+// getDispatcherTestRoutes returns the routes of a server which sends the
+// given updates one by one, then holds the getUpdates requests until
+// they're cancelled.
+func getDispatcherTestRoutes(updates []string) sibylRoutes {
+	var sent int32
+	return sibylRoutes{
+		"startPolling": func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"success":true,"result":{"polling_unique_id":1,"polling_access_hash":"hash"}}`))
+		},
+		"getUpdates": func(w http.ResponseWriter, r *http.Request) {
+			index := int(atomic.AddInt32(&sent, 1)) - 1
+			if index < len(updates) {
+				_, _ = w.Write([]byte(`{"success":true,"result":` + updates[index] + `}`))
+				return
+			}
+
+			<-r.Context().Done()
+		},
+	}
+}
+
+func TestDispatcherShutdown01(t *testing.T) {
+	client, _ := newTestClient(t, getDispatcherTestRoutes([]string{
+		`{"update_type":"scan_request_approved","update_data":{"unique_id":"a","target_user":1}}`,
+	}), nil)
+
+	var started, finished int32
+	dispatcher := sibylSystemGo.GetNewDispatcher(client)
+	dispatcher.AddHandler(sibylSystemGo.UpdateTypeScanRequestApproved,
+		func(client sibylSystemGo.SibylClient, ctx *sibylSystemGo.SibylUpdateContext) error {
+			atomic.StoreInt32(&started, 1)
+			time.Sleep(100 * time.Millisecond)
+			atomic.StoreInt32(&finished, 1)
+			return nil
+		})
+
+	if err := dispatcher.Listen(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := dispatcher.Run(context.Background()); err != sibylSystemGo.ErrDispatcherRunning {
+		t.Errorf("expected ErrDispatcherRunning, got %v", err)
+	}
+
+	if err := dispatcher.Listen(); err != sibylSystemGo.ErrDispatcherRunning {
+		t.Errorf("expected ErrDispatcherRunning, got %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(&started) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if atomic.LoadInt32(&started) == 0 {
+		t.Error("the handler hasn't been called")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := dispatcher.Shutdown(ctx); err != nil {
+		t.Errorf("failed to shutdown: %v", err)
+		return
+	}
+
+	if atomic.LoadInt32(&finished) != 1 || dispatcher.IsRunning() {
+		t.Error("expected shutdown to wait for the running handler")
+		return
+	}
+
+	if err := dispatcher.GetLastError(); err != nil {
+		t.Errorf("expected no error after shutdown, got %v", err)
+	}
+}
+
+func TestDispatcherRun01(t *testing.T) {
+	client, _ := newTestClient(t, getDispatcherTestRoutes(nil), nil)
+
+	dispatcher := sibylSystemGo.GetNewDispatcher(client)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := dispatcher.Run(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected the error of the context, got %v", err)
+		return
+	}
+
+	if time.Since(start) > time.Second {
+		t.Error("expected Run to cancel the in-flight long-poll")
+	}
+
+	// stopping a dispatcher which isn't running does nothing.
+	dispatcher.Stop()
+	if err := dispatcher.Shutdown(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDispatcherWorkers01(t *testing.T) {
+	var updates []string
+	for i := 0; i < 30; i++ {
+		updates = append(updates, fmt.Sprintf(`{"update_type":"scan_request_approved",`+
+			`"update_data":{"unique_id":"%d","target_user":%d}}`, i, i%3+1))
+	}
+
+	client, _ := newTestClient(t, getDispatcherTestRoutes(updates), nil)
+
+	var running, maxRunning, handled int32
+	mut := &sync.Mutex{}
+	received := make(map[int64][]string)
+
+	dispatcher := sibylSystemGo.GetNewDispatcher(client)
+	dispatcher.Workers = 2
+	dispatcher.QueueSize = 2
+	dispatcher.OrderByTarget = true
+	dispatcher.AddHandler(sibylSystemGo.UpdateTypeScanRequestApproved,
+		func(client sibylSystemGo.SibylClient, ctx *sibylSystemGo.SibylUpdateContext) error {
+			current := atomic.AddInt32(&running, 1)
+			for {
+				previous := atomic.LoadInt32(&maxRunning)
+				if current <= previous || atomic.CompareAndSwapInt32(&maxRunning, previous, current) {
+					break
+				}
+			}
+
+			time.Sleep(time.Millisecond)
+			mut.Lock()
+			target := ctx.GetTargetUser()
+			received[target] = append(received[target], ctx.ScanRequestApproved.UniqueId)
+			mut.Unlock()
+
+			atomic.AddInt32(&running, -1)
+			atomic.AddInt32(&handled, 1)
+			return nil
+		})
+
+	dispatcher.Listen()
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(&handled) < 30 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := dispatcher.Shutdown(context.Background()); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if atomic.LoadInt32(&handled) != 30 || atomic.LoadInt32(&maxRunning) > 2 {
+		t.Errorf("handled %d updates with %d running handlers", handled, maxRunning)
+		return
+	}
+
+	for target, ids := range received {
+		for i, id := range ids {
+			if id != fmt.Sprint(int64(i)*3+target-1) {
+				t.Errorf("updates of %d handled out of order: %v", target, ids)
+				break
+			}
+		}
+	}
+}
+
+func TestDispatcherBackoff01(t *testing.T) {
+	var polls, connected, disconnected int32
+	client, _ := newTestClient(t, sibylRoutes{
+		"startPolling": func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&polls, 1) == 3 {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte(`{"success":false,"error":{"code":500,"message":"down"}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"success":true,"result":{"polling_unique_id":1,"polling_access_hash":"hash"}}`))
+		},
+		"getUpdates": func(w http.ResponseWriter, r *http.Request) {
+			// drop the connection, as if the network is gone.
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				_ = conn.Close()
+			}
+		},
+	}, nil)
+
+	var gaveUp, startErr error
+	dispatcher := sibylSystemGo.GetNewDispatcher(client)
+	dispatcher.MaxConnectionTries = 4
+	dispatcher.Backoff = &sibylSystemGo.ConstantBackoff{Delay: time.Millisecond}
+	dispatcher.SetOnConnected(func(*sibylSystemGo.PollingIdentifier) { atomic.AddInt32(&connected, 1) })
+	dispatcher.SetOnDisconnected(func(err error) {
+		if sibylSystemGo.IsNetworkError(err) {
+			atomic.AddInt32(&disconnected, 1)
+		}
+	})
+	dispatcher.SetOnGiveUp(func(err error) { gaveUp = err })
+	dispatcher.SetOnStartFailed(func(err error) { startErr = err })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := dispatcher.Run(ctx)
+	giveUpErr := new(sibylSystemGo.GiveUpError)
+	if !errors.As(err, &giveUpErr) || gaveUp != err {
+		t.Errorf("expected a give up error, got %v", err)
+		return
+	}
+
+	// the connections which are lost before getting any updates don't
+	// reset the attempts; the failed start is the third one.
+	if atomic.LoadInt32(&connected) != 3 || atomic.LoadInt32(&disconnected) != 3 || giveUpErr.Attempts != 4 {
+		t.Errorf("unexpected lifecycle: %d connected, %d disconnected, %d attempts",
+			connected, disconnected, giveUpErr.Attempts)
+		return
+	}
+
+	if !sibylSystemGo.IsNetworkError(giveUpErr.Err) || dispatcher.GetLastError() != err {
+		t.Errorf("expected the last error to be the lost connection, got %v", giveUpErr.Err)
+	}
+
+	if startErr == nil || sibylSystemGo.IsNetworkError(startErr) || sibylSystemGo.IsNetworkError(context.Canceled) {
+		t.Errorf("expected server errors not to be network errors, got %v", startErr)
+	}
+}
+
+func TestExponentialBackoff01(t *testing.T) {
+	backoff := &sibylSystemGo.ExponentialBackoff{
+		InitialDelay:   time.Second,
+		MaxDelay:       5 * time.Second,
+		Multiplier:     2,
+		MaxElapsedTime: time.Minute,
+	}
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second}
+	for i, current := range expected {
+		delay, ok := backoff.NextDelay(i+1, 0)
+		if !ok || delay != current {
+			t.Errorf("unexpected delay of attempt %d: %v", i+1, delay)
+			return
+		}
+	}
+
+	if _, ok := backoff.NextDelay(5, time.Minute); ok {
+		t.Error("expected to give up after the max elapsed time")
+		return
+	}
+
+	backoff.Jitter = 0.5
+	for i := 0; i < 20; i++ {
+		delay, _ := backoff.NextDelay(1, 0)
+		if delay < 500*time.Millisecond || delay > 1500*time.Millisecond {
+			t.Errorf("delay out of the jitter range: %v", delay)
+			return
+		}
+	}
+}
+
+type testBanUpdate struct {
+	UserId int64  `json:"user_id"`
+	Reason string `json:"reason"`
+}
+
+func (u *testBanUpdate) GetTargetUser() int64 {
+	return u.UserId
+}
+
+func TestDispatcherTypedHandlers01(t *testing.T) {
+	const updateTypeBan sibylSystemGo.SibylUpdateType = "test_ban"
+	err := sibylSystemGo.RegisterUpdateDecoder(updateTypeBan, sibylSystemGo.JsonUpdateDecoder[testBanUpdate]())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = sibylSystemGo.RegisterUpdateDecoder(sibylSystemGo.UpdateTypeScanRequestRejected, nil)
+	if err != sibylSystemGo.ErrUpdateTypeExists {
+		t.Errorf("expected ErrUpdateTypeExists, got %v", err)
+		return
+	}
+
+	client, _ := newTestClient(t, getDispatcherTestRoutes([]string{
+		`{"update_type":"scan_request_rejected","update_data":{"unique_id":"r","target_user":7,"agent_reason":"no proof"}}`,
+		`{"update_type":"test_ban","update_data":{"user_id":8,"reason":"spam"}}`,
+		`{"update_type":"unknown_type","update_data":{"value":1}}`,
+	}), nil)
+
+	var rejected, banned, unknown int32
+	dispatcher := sibylSystemGo.GetNewDispatcher(client)
+	dispatcher.OnScanRequestRejected(func(client sibylSystemGo.SibylClient, update *sibylSystemGo.ScanRequestRejectedUpdate) error {
+		if update.TargetUser == 7 && update.AgentReason == "no proof" {
+			atomic.AddInt32(&rejected, 1)
+		}
+		return nil
+	})
+	sibylSystemGo.AddUpdateHandler(dispatcher, updateTypeBan,
+		func(client sibylSystemGo.SibylClient, update *testBanUpdate) error {
+			if update.GetTargetUser() == 8 && update.Reason == "spam" {
+				atomic.AddInt32(&banned, 1)
+			}
+			return nil
+		})
+	dispatcher.AddHandler("unknown_type",
+		func(client sibylSystemGo.SibylClient, ctx *sibylSystemGo.SibylUpdateContext) error {
+			if ctx.Value == nil && string(ctx.Data) == `{"value":1}` {
+				atomic.AddInt32(&unknown, 1)
+			}
+			return nil
+		})
+
+	dispatcher.Listen()
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(&unknown) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err = dispatcher.Shutdown(context.Background()); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if atomic.LoadInt32(&rejected) != 1 || atomic.LoadInt32(&banned) != 1 || atomic.LoadInt32(&unknown) != 1 {
+		t.Errorf("unexpected handled updates: %d rejected, %d banned, %d unknown", rejected, banned, unknown)
+	}
+}
+
+func TestNetworkError01(t *testing.T) {
+	// the transport errors which aren't about the network.
+	_, err := http.Get("foo://sibyl")
+	if err == nil || sibylSystemGo.IsNetworkError(err) {
+		t.Errorf("unsupported scheme detected as a network error: %v", err)
+		return
+	}
+
+	tlsServer := httptest.NewTLSServer(http.NotFoundHandler())
+	defer tlsServer.Close()
+	_, err = http.Get(tlsServer.URL)
+	if err == nil || sibylSystemGo.IsNetworkError(err) {
+		t.Errorf("certificate error detected as a network error: %v", err)
+		return
+	}
+
+	// a refused connection is.
+	server := httptest.NewServer(http.NotFoundHandler())
+	closedUrl := server.URL
+	server.Close()
+	_, err = http.Get(closedUrl)
+	if !sibylSystemGo.IsNetworkError(err) {
+		t.Errorf("expected a network error, got %v", err)
+	}
+}
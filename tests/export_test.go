@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	sibylSystemGo "github.com/ALiwoto/sibylSystemGo/sibylSystem"
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/banIO"
+)
+
+func TestExportBans01(t *testing.T) {
+	result := &sibylSystemGo.GetBansResult{
+		Users: []sibylSystemGo.BanInfo{
+			{
+				UserId:           1478,
+				Banned:           true,
+				Reason:           "spam | raid",
+				CrimeCoefficient: 350,
+				BanFlags:         []sibylSystemGo.BanFlag{sibylSystemGo.BanFlagSpam, sibylSystemGo.BanFlagRaid},
+				TargetType:       sibylSystemGo.EntityTypeBot,
+				BanSourceUrl:     "https://t.me/AnimeKaizoku/6176165",
+			},
+			{
+				UserId: 1479,
+				Banned: true,
+				Reason: "nsfw",
+			},
+		},
+	}
+
+	for _, format := range []banIO.Format{banIO.FormatCSV, banIO.FormatJSON, banIO.FormatNDJSON} {
+		buf := new(bytes.Buffer)
+		err := banIO.ExportBans(buf, result, format,
+			banIO.ColumnUserId, banIO.ColumnReason, banIO.ColumnTargetType, banIO.ColumnSrcUrl)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		records, err := banIO.ReadRecords(buf, format)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+
+		if len(records) != 2 || records[0].UserId != 1478 || records[0].Reason != "spam | raid" ||
+			records[0].TargetType != sibylSystemGo.EntityTypeBot ||
+			records[0].SrcUrl != "https://t.me/AnimeKaizoku/6176165" {
+			t.Errorf("%s: exported records can't be imported back correctly", format)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	err := banIO.ExportBans(buf, result, banIO.FormatMarkdown, banIO.ColumnUserId, banIO.ColumnReason)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "| user_id | reason |\n| --- | --- |\n| 1478 | spam \\| raid |\n| 1479 | nsfw |\n"
+	if buf.String() != expected {
+		t.Errorf("unexpected markdown output:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	err = banIO.ExportStats(buf, &sibylSystemGo.GetStatsResult{BannedCount: 2}, banIO.FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(buf.String(), "name,value\nbanned_count,2\n") {
+		t.Errorf("unexpected stats output:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,133 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	sibylSystemGo "github.com/ALiwoto/sibylSystemGo/sibylSystem"
+)
+
+func TestBanFlag01(t *testing.T) {
+	flag, err := sibylSystemGo.ParseBanFlag(" #spamBot ")
+	if err != nil || flag != sibylSystemGo.BanFlagSpamBot {
+		t.Errorf("unexpected flag: %q, %v", flag, err)
+		return
+	}
+
+	if flag.GetSeverity() != sibylSystemGo.SeverityHigh || flag.GetDescription() == "" {
+		t.Errorf("unexpected metadata for %s", flag)
+		return
+	}
+
+	flag, err = sibylSystemGo.ParseBanFlag("scam")
+	if err != sibylSystemGo.ErrUnknownBanFlag || flag.Valid() {
+		t.Errorf("expected unknown flag, got %q, %v", flag, err)
+		return
+	}
+
+	err = sibylSystemGo.RegisterBanFlag(&sibylSystemGo.BanFlagInfo{
+		Flag:        "scam",
+		Description: "Scamming users",
+		Severity:    sibylSystemGo.SeverityCritical,
+	})
+	if err != nil || !sibylSystemGo.BanFlag("SCAM").Valid() {
+		t.Errorf("failed to register a custom flag: %v", err)
+		return
+	}
+
+	err = sibylSystemGo.RegisterBanFlag(&sibylSystemGo.BanFlagInfo{Flag: sibylSystemGo.BanFlagSpam})
+	if err != sibylSystemGo.ErrBanFlagExists {
+		t.Errorf("expected ErrBanFlagExists, got %v", err)
+	}
+}
+
+func TestBanFlag02(t *testing.T) {
+	data := []byte(`{"ban_flags":["spam","#raid","UNHEARD"]}`)
+	info := new(sibylSystemGo.BanInfo)
+	err := json.Unmarshal(data, info)
+	if err != nil || len(info.BanFlags) != 3 || info.BanFlags[0] != sibylSystemGo.BanFlagSpam ||
+		info.BanFlags[1] != sibylSystemGo.BanFlagRaid || info.BanFlags[2] != "UNHEARD" {
+		t.Errorf("unexpected flags: %v, %v", info.BanFlags, err)
+		return
+	}
+
+	sibylSystemGo.SetBanFlagsStrictMode(true)
+	defer sibylSystemGo.SetBanFlagsStrictMode(false)
+
+	err = json.Unmarshal(data, info)
+	if err != sibylSystemGo.ErrUnknownBanFlag {
+		t.Errorf("expected ErrUnknownBanFlag in strict mode, got %v", err)
+		return
+	}
+
+	_, err = json.Marshal(sibylSystemGo.BanFlag("UNHEARD"))
+	if err == nil {
+		t.Error("expected an error marshalling an unknown flag in strict mode")
+	}
+}
+
+func TestReason01(t *testing.T) {
+	builder := sibylSystemGo.NewReasonBuilder().
+		AddFlags(sibylSystemGo.BanFlagSpamBot, "raid", sibylSystemGo.BanFlagSpamBot).
+		AddText("  joined and spammed links ").
+		SetPerma(true)
+
+	reason, err := builder.Build()
+	if err != nil || reason != "#SPAMBOT #RAID joined and spammed links perma" {
+		t.Errorf("unexpected reason: %q, %v", reason, err)
+		return
+	}
+
+	_, err = sibylSystemGo.NewReasonBuilder().AddFlags("NOTAFLAG").Build()
+	if err != sibylSystemGo.ErrUnknownBanFlag {
+		t.Errorf("expected ErrUnknownBanFlag, got %v", err)
+		return
+	}
+
+	parsed := sibylSystemGo.ParseReason("Spamming crypto #spam, #raid #crypto (Permanent)")
+	if len(parsed.Flags) != 2 || parsed.Flags[0] != sibylSystemGo.BanFlagSpam ||
+		parsed.Flags[1] != sibylSystemGo.BanFlagRaid || !parsed.Perma {
+		t.Errorf("unexpected parsed reason: %+v", parsed)
+		return
+	}
+
+	if len(parsed.UnknownTags) != 1 || parsed.UnknownTags[0] != "#crypto" ||
+		parsed.Text != "Spamming crypto #crypto (Permanent)" {
+		t.Errorf("unexpected unknown tags or text: %+v", parsed)
+		return
+	}
+
+	if sibylSystemGo.ValidateReason("   ") != sibylSystemGo.ErrNoReason {
+		t.Error("expected ErrNoReason for a blank reason")
+	}
+}
+
+func TestBanTargetReason01(t *testing.T) {
+	target := &sibylSystemGo.BanTarget{
+		Reason: "spam wave #SpamBot",
+		Flags:  []sibylSystemGo.BanFlag{"spambot", "#nsfw ", ""},
+	}
+
+	if reason := target.GetReason(); reason != "spam wave #SpamBot #NSFW" {
+		t.Errorf("unexpected reason: %q", reason)
+		return
+	}
+
+	// a flag which is only a prefix of a tag in the reason is still added.
+	target = &sibylSystemGo.BanTarget{
+		Reason: "spam wave #SPAMBOT",
+		Flags:  []sibylSystemGo.BanFlag{"SPAM"},
+	}
+	if reason := target.GetReason(); reason != "spam wave #SPAMBOT #SPAM" {
+		t.Errorf("unexpected reason with a prefix tag: %q", reason)
+		return
+	}
+
+	target = &sibylSystemGo.BanTarget{
+		Reason: "spam wave",
+		Flags:  []sibylSystemGo.BanFlag{"SPAM", "#spam", "SPAM"},
+	}
+	if reason := target.GetReason(); reason != "spam wave #SPAM" {
+		t.Errorf("unexpected reason with duplicate flags: %q", reason)
+	}
+}
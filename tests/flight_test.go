@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sibylSystemGo "github.com/ALiwoto/sibylSystemGo/sibylSystem"
+)
+
+func TestGetInfoDeduplication01(t *testing.T) {
+	var hits int32
+	client, _ := newTestClient(t, sibylRoutes{
+		"getInfo": func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&hits, 1)
+			time.Sleep(100 * time.Millisecond)
+			_, _ = w.Write([]byte(`{"success":true,"result":{"user_id":1478,"banned":true}}`))
+		},
+	}, nil)
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			info, err := client.GetInfo(1478)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			if info.UserId != 1478 || !info.Banned {
+				t.Errorf("unexpected result: %v", info)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if hits != 1 {
+		t.Errorf("expected exactly 1 request to the server, got %d", hits)
+	}
+}
+
+func TestGetInfoDeduplication02(t *testing.T) {
+	client, _ := newTestClient(t, sibylRoutes{
+		"getInfo": func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+			_, _ = w.Write([]byte(`{"success":true,"result":{"user_id":1478}}`))
+		},
+	}, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.GetInfo(1478)
+		done <- err
+	}()
+
+	_, err := client.GetInfoWithContext(ctx, 1478)
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+
+	if err = <-done; err != nil {
+		t.Errorf("cancelling one caller should not affect the other one: %v", err)
+	}
+}
+
+func TestGetInfoDeduplication03(t *testing.T) {
+	client, _ := newTestClient(t, sibylRoutes{
+		"getInfo": func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(50 * time.Millisecond)
+			_, _ = w.Write([]byte(`{"success":true,"result":{"user_id":1478,"banned":true,"ban_flags":["SPAM","NSFW"]}}`))
+		},
+	}, &sibylSystemGo.SibylConfig{
+		InfoCacheTTL: time.Minute,
+	})
+
+	// the callers sharing a request don't share the flags.
+	results := make([]*sibylSystemGo.GetInfoResult, 2)
+	wg := &sync.WaitGroup{}
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = client.GetInfo(1478)
+		}(i)
+	}
+	wg.Wait()
+
+	if results[0] == nil || results[1] == nil || len(results[0].BanFlags) != 2 {
+		t.Fatalf("unexpected results: %v", results)
+	}
+
+	results[0].BanFlags[0] = "TAMPERED"
+	if results[1].BanFlags[0] != "SPAM" {
+		t.Errorf("the flags are shared between the callers: %v", results[1].BanFlags)
+		return
+	}
+
+	// and the cached result can't be changed by the callers.
+	info, err := client.GetInfo(1478)
+	if err != nil || info.BanFlags[0] != "SPAM" {
+		t.Errorf("the cached flags have been changed: %v, %v", info, err)
+		return
+	}
+
+	info.BanFlags[1] = "TAMPERED"
+	cached, _ := client.GetCachedInfo(1478)
+	if cached == nil || cached.BanFlags[1] != "NSFW" {
+		t.Errorf("the cached flags have been changed: %v", cached)
+	}
+}
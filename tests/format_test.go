@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"testing"
+
+	sibylSystemGo "github.com/ALiwoto/sibylSystemGo/sibylSystem"
+	"golang.org/x/text/language"
+)
+
+func TestFormatter01(t *testing.T) {
+	info := &sibylSystemGo.GetInfoResult{
+		CrimeCoefficient: 320,
+		TargetType:       sibylSystemGo.EntityTypeBot,
+		BanFlags:         []sibylSystemGo.BanFlag{sibylSystemGo.BanFlagSpam, sibylSystemGo.BanFlagMalImp},
+	}
+
+	tests := []struct {
+		f        sibylSystemGo.Formatter
+		flags    string
+		estimate string
+		mention  string
+	}{
+		{
+			f:        sibylSystemGo.MarkdownFormatter{},
+			flags:    "`SPAM`, `MALIMP`",
+			estimate: "over `300`",
+			mention:  "[a\\_b](tg://user?id=12)",
+		},
+		{
+			f:        sibylSystemGo.HTMLFormatter{},
+			flags:    "<code>SPAM</code>, <code>MALIMP</code>",
+			estimate: "over <code>300</code>",
+			mention:  `<a href="tg://user?id=12">a_b</a>`,
+		},
+		{
+			f:        sibylSystemGo.PlainFormatter{},
+			flags:    "SPAM, MALIMP",
+			estimate: "over 300",
+			mention:  "a_b (tg://user?id=12)",
+		},
+	}
+
+	for _, current := range tests {
+		if got := info.FormatFlagsWith(current.f); got != current.flags {
+			t.Errorf("%s flags: expected %q, got %q", current.f.GetParseMode(), current.flags, got)
+		}
+
+		if got := info.FormatEstimateWith(current.f, language.English); got != current.estimate {
+			t.Errorf("%s estimate: expected %q, got %q", current.f.GetParseMode(), current.estimate, got)
+		}
+
+		if got := sibylSystemGo.FormatMention(current.f, "a_b", 12); got != current.mention {
+			t.Errorf("%s mention: expected %q, got %q", current.f.GetParseMode(), current.mention, got)
+		}
+	}
+
+	html := sibylSystemGo.HTMLFormatter{}
+	if got := info.TargetType.FormatWith(html, language.English); got != "Bot" {
+		t.Errorf("unexpected entity type: %q", got)
+	}
+
+	if got := sibylSystemGo.FormatText(html, sibylSystemGo.StyleBold, "<Owner>"); got != "<b>&lt;Owner&gt;</b>" {
+		t.Errorf("unexpected escaping: %q", got)
+	}
+}
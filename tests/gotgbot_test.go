@@ -0,0 +1,164 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/sibylBot"
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/sibylGotgbot"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+func newGotgbotTestBot(t *testing.T, botServer *fakeBotApiServer) *gotgbot.Bot {
+	bot, err := gotgbot.NewBot("123:abc", &gotgbot.BotOpts{
+		Client:            *botServer.Client(),
+		DisableTokenCheck: true,
+		DefaultRequestOpts: &gotgbot.RequestOpts{
+			APIURL: botServer.URL,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return bot
+}
+
+func TestGotgbotAdapter01(t *testing.T) {
+	var reported int64
+	botServer := newFakeBotApiServer()
+	defer botServer.Close()
+	client, _ := newTestClient(t, getBotTestRoutes(&reported), nil)
+	bot := newGotgbotTestBot(t, botServer)
+	adapter, err := sibylGotgbot.NewAdapter(client, bot, &sibylBot.AdapterConfig{
+		Enforcers: []int64{7},
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	handler, err := sibylGotgbot.NewHandler(adapter, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// the handlers of the bot only see the messages of allowed users.
+	var seen []int64
+	dispatcher := ext.NewDispatcher(nil)
+	dispatcher.AddHandlerToGroup(handler, sibylGotgbot.DefaultHandlerGroup)
+	dispatcher.AddHandler(&testGotgbotHandler{seen: &seen})
+
+	chat := gotgbot.Chat{Id: -1001, Type: "supergroup"}
+	updates := []*gotgbot.Update{
+		{UpdateId: 1, Message: &gotgbot.Message{
+			MessageId: 1, Chat: chat, From: &gotgbot.User{Id: 100}, Text: "buy crypto",
+		}},
+		{UpdateId: 2, Message: &gotgbot.Message{
+			MessageId: 2, Chat: chat, From: &gotgbot.User{Id: 200}, Text: "hello",
+		}},
+		{UpdateId: 3, Message: &gotgbot.Message{
+			MessageId: 3, Chat: chat, From: &gotgbot.User{Id: 7}, Text: "/report spam",
+			ReplyToMessage: &gotgbot.Message{MessageId: 2, Chat: chat, From: &gotgbot.User{Id: 300}},
+		}},
+	}
+
+	for _, current := range updates {
+		err = dispatcher.ProcessUpdate(bot, current, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	calls, texts := botServer.get()
+	if len(seen) != 2 || seen[0] != 200 || seen[1] != 7 {
+		t.Errorf("unexpected messages reaching the bot: %v", seen)
+		return
+	}
+
+	if len(calls) < 2 || calls[0] != "banChatMember:100" ||
+		!strings.HasPrefix(texts[0], "[100](tg://user?id=100) has been banned") {
+		t.Errorf("unexpected calls: %v, %v", calls, texts)
+		return
+	}
+
+	if atomic.LoadInt64(&reported) != 300 {
+		t.Errorf("expected user 300 to be reported, got %d", reported)
+	}
+}
+
+func TestGotgbotAdapter02(t *testing.T) {
+	var reported int64
+	botServer := newFakeBotApiServer()
+	defer botServer.Close()
+	client, _ := newTestClient(t, getBotTestRoutes(&reported), nil)
+	bot := newGotgbotTestBot(t, botServer)
+	adapter, err := sibylGotgbot.NewAdapter(client, bot, &sibylBot.AdapterConfig{
+		Enforcers: []int64{7},
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// the command handlers don't check the sender.
+	dispatcher := ext.NewDispatcher(nil)
+	for _, current := range sibylGotgbot.GetCommandHandlers(adapter) {
+		dispatcher.AddHandler(current)
+	}
+
+	chat := gotgbot.Chat{Id: -1001, Type: "supergroup"}
+	err = dispatcher.ProcessUpdate(bot, &gotgbot.Update{UpdateId: 1, Message: &gotgbot.Message{
+		MessageId: 1, Chat: chat, From: &gotgbot.User{Id: 7}, Text: "/scan 100",
+	}}, nil)
+	calls, texts := botServer.get()
+	if err != nil || len(calls) != 2 || calls[0] != "banChatMember:100" ||
+		!strings.HasPrefix(texts[0], "[100](tg://user?id=100) has been banned") {
+		t.Errorf("unexpected scan: %v, %v, %v", calls, texts, err)
+		return
+	}
+
+	msg := &gotgbot.Message{Chat: chat, From: &gotgbot.User{Id: 7}, Caption: "/SibylInfo 100"}
+	if !sibylGotgbot.FilterCommands(adapter)(msg) || !sibylGotgbot.FilterEnforcers(adapter)(msg) ||
+		sibylGotgbot.FilterNewMembers(msg) {
+		t.Error("unexpected result of the filters")
+		return
+	}
+
+	converted := sibylGotgbot.ConvertMessage(msg)
+	if converted.SenderId != 7 || converted.ChatId != -1001 || converted.Text != "/SibylInfo 100" {
+		t.Errorf("unexpected converted message: %+v", converted)
+		return
+	}
+
+	// errors of the bot api are the errors of gotgbot.
+	api := sibylGotgbot.NewBotApi(bot)
+	err = api.BanChatMember(context.Background(), 0, 100)
+	var tgErr *gotgbot.TelegramError
+	if !errors.As(err, &tgErr) || tgErr.Code != 400 {
+		t.Errorf("expected a telegram error, got %v", err)
+	}
+}
+
+type testGotgbotHandler struct {
+	seen *[]int64
+}
+
+func (h *testGotgbotHandler) CheckUpdate(b *gotgbot.Bot, ctx *ext.Context) bool {
+	return ctx.Message != nil
+}
+
+func (h *testGotgbotHandler) HandleUpdate(b *gotgbot.Bot, ctx *ext.Context) error {
+	*h.seen = append(*h.seen, ctx.Message.From.Id)
+	return nil
+}
+
+func (h *testGotgbotHandler) Name() string {
+	return "test"
+}
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/sibylGuard"
+)
+
+func getGuardTestRoutes() sibylRoutes {
+	return sibylRoutes{
+		"getInfo": func(w http.ResponseWriter, r *http.Request) {
+			switch r.Header.Get("user-id") {
+			case "100":
+				_, _ = w.Write([]byte(`{"success":true,"result":{"user_id":100,"banned":true,` +
+					`"reason":"spam bot","crime_coefficient":150,"ban_flags":["SPAMBOT"]}}`))
+			case "200":
+				_, _ = w.Write([]byte(`{"success":true,"result":{"user_id":200,"banned":false}}`))
+			default:
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte(`not json`))
+			}
+		},
+	}
+}
+
+func TestGuard01(t *testing.T) {
+	client, _ := newTestClient(t, getGuardTestRoutes(), nil)
+
+	guard, err := sibylGuard.NewGuard(client, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	decision := guard.CheckJoin(context.Background(), -1001, 100, false)
+	if decision.Action != sibylGuard.ActionBan || decision.Err != nil {
+		t.Errorf("expected ban, got %s (%v)", decision.Action, decision.Err)
+		return
+	}
+
+	if !strings.HasPrefix(decision.Reason, "100 has been banned") ||
+		!strings.Contains(decision.Reason, "Reason: spam bot") {
+		t.Errorf("unexpected reason: %q", decision.Reason)
+	}
+
+	decision = guard.CheckMessage(context.Background(), -1001, 200, false)
+	if !decision.IsAllowed() || decision.Reason != "" {
+		t.Errorf("expected allow, got %s: %q", decision.Action, decision.Reason)
+	}
+
+	// fail open
+	decision = guard.CheckJoin(context.Background(), -1001, 300, false)
+	if !decision.IsAllowed() || !decision.IsFailure() {
+		t.Errorf("expected allowed failure, got %s (%v)", decision.Action, decision.Err)
+	}
+
+	if guard.Check(context.Background(), &sibylGuard.Event{}).Err != sibylGuard.ErrInvalidEvent {
+		t.Error("expected ErrInvalidEvent")
+	}
+}
+
+func TestGuard02(t *testing.T) {
+	client, _ := newTestClient(t, getGuardTestRoutes(), nil)
+
+	guard, err := sibylGuard.NewGuard(client, &sibylGuard.GuardConfig{
+		Policy:           sibylGuard.BandPolicy,
+		FailurePolicy:    sibylGuard.FailClosed,
+		FailClosedAction: sibylGuard.ActionKick,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// latent criminals are kicked on join, but allowed to send messages.
+	if action := guard.CheckJoin(context.Background(), -1001, 100, false).Action; action != sibylGuard.ActionKick {
+		t.Errorf("expected kick on join, got %s", action)
+	}
+
+	if action := guard.CheckMessage(context.Background(), -1001, 100, false).Action; action != sibylGuard.ActionAllow {
+		t.Errorf("expected allow on message, got %s", action)
+	}
+
+	decision := guard.CheckJoin(context.Background(), -1001, 300, false)
+	if decision.Action != sibylGuard.ActionKick || !strings.Contains(decision.Reason, "couldn't be checked") {
+		t.Errorf("expected fail closed kick, got %s: %q", decision.Action, decision.Reason)
+	}
+}
@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	sibylSystemGo "github.com/ALiwoto/sibylSystemGo/sibylSystem"
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/banIO"
+)
+
+func TestImportPreview01(t *testing.T) {
+	const csvData = `id,reason,src,type
+1,spam,https://t.me/c/1/2,user
+2,raid,,bot
+3,nsfw,,0
+4,,,user
+5,spam,not-a-url,user
+1,again,,user
+`
+
+	client, _ := newTestClient(t, sibylRoutes{
+		"getInfo": func(w http.ResponseWriter, r *http.Request) {
+			switch r.Header.Get("user-id") {
+			case "2":
+				_, _ = w.Write([]byte(`{"success":true,"result":{"user_id":2,"banned":true,"reason":"raid"}}`))
+			case "3":
+				_, _ = w.Write([]byte(`{"success":true,"result":{"user_id":3,"banned":true,"reason":"spam"}}`))
+			default:
+				_, _ = w.Write([]byte(`{"success":true,"result":{"user_id":1,"banned":false}}`))
+			}
+		},
+	}, nil)
+
+	records, err := banIO.ReadRecords(strings.NewReader(csvData), banIO.FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := banIO.Preview(context.Background(), client, records)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(plan.Invalid) != 3 {
+		t.Errorf("expected 3 invalid records, got %d", len(plan.Invalid))
+	}
+
+	if plan.Count(banIO.PlanActionBan) != 1 || plan.Count(banIO.PlanActionSkip) != 1 ||
+		plan.Count(banIO.PlanActionUpdate) != 1 {
+		t.Errorf("unexpected plan actions")
+	}
+
+	targets := plan.GetTargets()
+	if len(targets) != 2 || targets[0].Config.SrcUrl != "https://t.me/c/1/2" ||
+		targets[1].Config.TargetType != sibylSystemGo.EntityTypeUser {
+		t.Errorf("unexpected targets")
+	}
+}
+
+func TestImportPreview02(t *testing.T) {
+	const csvData = `id,reason,type
+1,spam #SPAM,
+2,spam #NotAFlag,user
+3,spam,robot
+`
+
+	records, err := banIO.ReadRecords(strings.NewReader(csvData), banIO.FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the reasons are checked the same way as Ban does.
+	sibylSystemGo.SetBanFlagsStrictMode(true)
+	defer sibylSystemGo.SetBanFlagsStrictMode(false)
+
+	valid, invalid := banIO.ValidateRecords(records)
+	if len(valid) != 1 || valid[0].TargetType != sibylSystemGo.EntityTypeUser || len(invalid) != 2 {
+		t.Fatalf("unexpected records: %v, %v", valid, invalid)
+	}
+
+	if !errors.Is(invalid[0].Err, sibylSystemGo.ErrUnknownReasonFlag) ||
+		!errors.Is(invalid[1].Err, sibylSystemGo.ErrInvalidEntityType) {
+		t.Errorf("unexpected errors: %v, %v", invalid[0].Err, invalid[1].Err)
+	}
+}
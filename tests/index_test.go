@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/binary"
+	"path/filepath"
+	"testing"
+
+	sibylSystemGo "github.com/ALiwoto/sibylSystemGo/sibylSystem"
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/banIndex"
+)
+
+func TestBanIndex01(t *testing.T) {
+	result := &sibylSystemGo.GetBansResult{}
+	for i := int64(1); i <= 5000; i++ {
+		result.Users = append(result.Users, sibylSystemGo.BanInfo{
+			UserId:           i * 3,
+			Banned:           true,
+			CrimeCoefficient: sibylSystemGo.CrimeCoefficient(100 + i%500),
+			BanFlags:         []sibylSystemGo.BanFlag{sibylSystemGo.BanFlagSpam, sibylSystemGo.BanFlagRaid},
+		})
+	}
+	result.Users = append(result.Users, sibylSystemGo.BanInfo{UserId: 2, Banned: false})
+
+	index := banIndex.Build(result)
+	path := filepath.Join(t.TempDir(), "bans.sbix")
+	if err := index.SaveToFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	mapped, err := banIndex.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mapped.Close()
+
+	if mapped.Length() != 5000 {
+		t.Fatalf("expected 5000 entries, got %d", mapped.Length())
+	}
+
+	entry, ok := mapped.Lookup(300)
+	if !ok {
+		t.Fatal("expected user 300 to be banned")
+	}
+
+	if entry.CrimeCoefficient != 200 || !entry.Flags.Has(sibylSystemGo.BanFlagRaid) ||
+		entry.Flags.Has(sibylSystemGo.BanFlagNSFW) {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+
+	if mapped.IsBanned(2) || mapped.IsBanned(301) || mapped.IsBanned(-3) {
+		t.Error("unexpected banned user")
+	}
+
+	falsePositives := 0
+	for i := int64(1); i <= 5000; i++ {
+		if mapped.MightBeBanned(i*3 + 1) {
+			falsePositives++
+		}
+	}
+
+	if falsePositives > 250 {
+		t.Errorf("too many false positives in the pre-filter: %d", falsePositives)
+	}
+}
+
+func TestBanIndexCorrupted01(t *testing.T) {
+	result := &sibylSystemGo.GetBansResult{
+		Users: []sibylSystemGo.BanInfo{{UserId: 1, Banned: true}, {UserId: 2, Banned: true}},
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := banIndex.Build(result).WriteTo(buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	// too many hashes.
+	tmp := append([]byte{}, data...)
+	binary.LittleEndian.PutUint32(tmp[24:], 1<<31)
+	if _, err := banIndex.Load(tmp); err != banIndex.ErrCorruptedIndex {
+		t.Errorf("expected ErrCorruptedIndex for the hashes, got %v", err)
+	}
+
+	// no bloom filter, with the length of the data matching it.
+	bloomBytes := int(binary.LittleEndian.Uint64(data[16:]) / 8)
+	tmp = append([]byte{}, data[:len(data)-bloomBytes]...)
+	binary.LittleEndian.PutUint64(tmp[16:], 0)
+	if _, err := banIndex.Load(tmp); err != banIndex.ErrCorruptedIndex {
+		t.Errorf("expected ErrCorruptedIndex for the bloom bits, got %v", err)
+	}
+}
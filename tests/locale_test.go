@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	sibylSystemGo "github.com/ALiwoto/sibylSystemGo/sibylSystem"
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+)
+
+func TestLocale01(t *testing.T) {
+	err := sibylSystemGo.RegisterLocale(language.Spanish, map[string]string{
+		sibylSystemGo.MsgKeyYes:               "Sí",
+		sibylSystemGo.MsgKeyListLastSeparator: " y ",
+		sibylSystemGo.MsgKeyEstimateOver:      "más de ",
+		sibylSystemGo.MsgKeyTimeAgo:           "hace %s",
+		"flag.spam":                           "spam",
+		"flag.raid":                           "asalto",
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = sibylSystemGo.RegisterMessage(language.Spanish, sibylSystemGo.MsgKeyUnitDay,
+		plural.Selectf(1, "%d", "one", "%d día", "other", "%d días"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	es := language.MustParse("es-MX")
+	if got := sibylSystemGo.EntityTypeBot.IsBotStrIn(es); got != "Sí" {
+		t.Errorf("expected localized yes, got %q", got)
+	}
+
+	// missing translations fall back to english.
+	if got := sibylSystemGo.EntityTypeBot.IsUserStrIn(es); got != "No" {
+		t.Errorf("expected english no, got %q", got)
+	}
+
+	if got := sibylSystemGo.CrimeCoefficient(250).EstimateIn(es); got != "más de 200" {
+		t.Errorf("unexpected estimate: %q", got)
+	}
+
+	info := &sibylSystemGo.GetInfoResult{
+		BanFlags: []sibylSystemGo.BanFlag{
+			sibylSystemGo.BanFlagSpam,
+			sibylSystemGo.BanFlagNSFW,
+			sibylSystemGo.BanFlagRaid,
+		},
+	}
+	if got := info.FormatCuteFlagsIn(es).ToString(); got != "spam, nsfw y asalto" {
+		t.Errorf("unexpected flags: %q", got)
+	}
+
+	if got := info.FormatCuteFlags().ToString(); got != "spam, nsfw and raid" {
+		t.Errorf("unexpected english flags: %q", got)
+	}
+
+	now := time.Date(2022, 6, 10, 10, 0, 0, 0, time.UTC)
+	date := sibylSystemGo.NewSibylTime(now.Add(-72 * time.Hour))
+	if got := date.FormatRelativeIn(es, now); got != "hace 3 días" {
+		t.Errorf("unexpected relative time: %q", got)
+	}
+
+	if got := date.FormatRelative(now); got != "3 days ago" {
+		t.Errorf("unexpected english relative time: %q", got)
+	}
+}
@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"net/http"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/banMirror"
+)
+
+func TestMirrorSync01(t *testing.T) {
+	responses := []string{
+		`{"success":true,"result":{"users":[
+			{"user_id":1,"banned":true,"reason":"spam","crime_coefficient":150},
+			{"user_id":2,"banned":true,"reason":"raid","crime_coefficient":300}
+		]}}`,
+		`{"success":true,"result":{"users":[
+			{"user_id":2,"banned":true,"reason":"raid, mass add","crime_coefficient":350},
+			{"user_id":3,"banned":true,"reason":"nsfw","crime_coefficient":200}
+		]}}`,
+	}
+
+	var current int32
+	client, server := newTestClient(t, sibylRoutes{
+		"getBans": func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(responses[atomic.LoadInt32(&current)]))
+		},
+	}, nil)
+
+	storePath := filepath.Join(t.TempDir(), "bans.json")
+	mirror, err := banMirror.NewMirror(client, &banMirror.MirrorConfig{
+		Store: banMirror.NewFileStore(storePath),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := mirror.Sync()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(diff.Added) != 2 || len(diff.Removed) != 0 || len(diff.Changed) != 0 {
+		t.Fatalf("unexpected first diff: %d added, %d removed, %d changed",
+			len(diff.Added), len(diff.Removed), len(diff.Changed))
+	}
+
+	atomic.StoreInt32(&current, 1)
+	var events int
+	mirror.SetOnChange(func(change *banMirror.BanChange) {
+		events++
+	})
+
+	diff, err = mirror.Sync()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(diff.Added) != 1 || diff.Added[0].UserId != 3 {
+		t.Errorf("expected user 3 to be added")
+	}
+
+	if len(diff.Removed) != 1 || diff.Removed[0].UserId != 1 {
+		t.Errorf("expected user 1 to be removed")
+	}
+
+	if len(diff.Changed) != 1 || diff.Changed[0].Current.CrimeCoefficient != 350 {
+		t.Errorf("expected user 2 to be changed")
+	}
+
+	if events != 3 {
+		t.Errorf("expected 3 change events, got %d", events)
+	}
+
+	// the server is gone, lookups should still be served from the
+	// persisted snapshot.
+	server.Close()
+
+	offline, err := banMirror.NewMirror(client, &banMirror.MirrorConfig{
+		Store: banMirror.NewFileStore(storePath),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = offline.Sync(); err == nil {
+		t.Error("expected sync to fail while the server is down")
+	}
+
+	if !offline.IsBanned(2) || !offline.IsBanned(3) || offline.IsBanned(1) {
+		t.Error("offline lookups don't match the last synced state")
+	}
+}
+
+func TestMirrorStop01(t *testing.T) {
+	requested := make(chan struct{}, 1)
+	client, _ := newTestClient(t, sibylRoutes{
+		"getBans": func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case requested <- struct{}{}:
+			default:
+			}
+			<-r.Context().Done()
+		},
+	}, nil)
+
+	mirror, err := banMirror.NewMirror(client, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = mirror.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the callbacks can be set while the mirror is running.
+	var failed int32
+	mirror.SetOnSyncFailed(func(err error) {
+		atomic.AddInt32(&failed, 1)
+	})
+	mirror.SetOnSynced(func(*banMirror.MirrorDiff) {})
+
+	<-requested
+	mirror.Stop()
+
+	if atomic.LoadInt32(&failed) != 0 || mirror.GetLastError() != nil {
+		t.Errorf("cancelled sync has been reported as failed: %v", mirror.GetLastError())
+	}
+}
@@ -0,0 +1,185 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	sibylSystemGo "github.com/ALiwoto/sibylSystemGo/sibylSystem"
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/banPolicy"
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/sibylGuard"
+)
+
+const testPolicyYAML = `
+default:
+  default_action: allow
+  rules:
+    - name: dangerous
+      min_coefficient: 300
+      action: ban
+chats:
+  -100123:
+    inherit: true
+    rules:
+      - name: spam-bots
+        flags: ["#spambot"]
+        target_types: [bot]
+        action: kick
+      - name: trolls
+        query: "flag:trolling"
+        reason_contains: [insult]
+        action: mute
+`
+
+func TestBanPolicy01(t *testing.T) {
+	set, err := banPolicy.ParsePolicySet([]byte(testPolicyYAML), banPolicy.FormatYAML)
+	if err != nil {
+		t.Errorf("failed to parse the policy set: %v", err)
+		return
+	}
+
+	engine, err := banPolicy.NewEngine(set)
+	if err != nil {
+		t.Errorf("failed to create the engine: %v", err)
+		return
+	}
+
+	bot := &sibylSystemGo.GetInfoResult{
+		UserId:           1,
+		Banned:           true,
+		CrimeCoefficient: 350,
+		TargetType:       sibylSystemGo.EntityTypeBot,
+		BanFlags:         []sibylSystemGo.BanFlag{sibylSystemGo.BanFlagSpamBot},
+	}
+
+	evaluation := engine.Evaluate(-100123, bot)
+	if evaluation.Action != sibylGuard.ActionKick || evaluation.GetRuleName() != "spam-bots" ||
+		len(evaluation.Matches) != 2 {
+		t.Errorf("unexpected evaluation in the chat: %+v", evaluation)
+		return
+	}
+
+	evaluation = engine.Evaluate(-100456, bot)
+	if evaluation.Action != sibylGuard.ActionBan || evaluation.GetRuleName() != "dangerous" {
+		t.Errorf("unexpected evaluation with the default policy: %+v", evaluation)
+		return
+	}
+
+	bot.Banned = false
+	if !engine.Evaluate(-100123, bot).IsAllowed() {
+		t.Error("expected unbanned users to be allowed")
+		return
+	}
+
+	troll := &sibylSystemGo.GetInfoResult{
+		UserId:           2,
+		Banned:           true,
+		Reason:           "Insulting members",
+		CrimeCoefficient: 150,
+		BanFlags:         []sibylSystemGo.BanFlag{sibylSystemGo.BanFlagTrolling},
+	}
+
+	evaluation = engine.Evaluate(-100123, troll)
+	if evaluation.Action != sibylGuard.ActionMute {
+		t.Errorf("expected the troll to be muted, got %v", evaluation.Action)
+	}
+}
+
+func TestBanPolicy02(t *testing.T) {
+	data := []byte(`{"default":{"rules":[{"name":"bad","flags":["NOTAFLAG"],"action":"ban"}]}}`)
+	set, err := banPolicy.ParsePolicySet(data, banPolicy.FormatJSON)
+	if err != nil {
+		t.Errorf("failed to parse the policy set: %v", err)
+		return
+	}
+
+	_, err = banPolicy.NewEngine(set)
+	ruleErr := new(banPolicy.RuleError)
+	if !errors.As(err, &ruleErr) || ruleErr.Name != "bad" || !errors.Is(err, sibylSystemGo.ErrUnknownBanFlag) {
+		t.Errorf("expected a rule error for the unknown flag, got %v", err)
+		return
+	}
+
+	_, err = banPolicy.ParsePolicySet([]byte(`{"default":{"rules":[{"action":"nuke"}]}}`), banPolicy.FormatJSON)
+	if !errors.Is(err, sibylGuard.ErrInvalidAction) {
+		t.Errorf("expected ErrInvalidAction, got %v", err)
+		return
+	}
+
+	set, _ = banPolicy.ParsePolicySet([]byte(`{"default":{"rules":[{"target_types":["99"],"action":"ban"}]}}`),
+		banPolicy.FormatJSON)
+	_, err = banPolicy.NewEngine(set)
+	if !errors.Is(err, sibylSystemGo.ErrInvalidEntityType) {
+		t.Errorf("expected ErrInvalidEntityType, got %v", err)
+		return
+	}
+
+	set, _ = banPolicy.ParsePolicySet([]byte(`{"default":{"rules":[{"name":"silent","flags":["SPAM"]}]}}`),
+		banPolicy.FormatJSON)
+	_, err = banPolicy.NewEngine(set)
+	if !errors.As(err, &ruleErr) || ruleErr.Name != "silent" || !errors.Is(err, banPolicy.ErrNoAction) {
+		t.Errorf("expected a rule error for the missing action, got %v", err)
+		return
+	}
+
+	ban := sibylGuard.ActionBan
+	engine, _ := banPolicy.NewEngine(nil)
+	err = engine.SetPolicy(banPolicy.GlobalChatId, &banPolicy.Policy{
+		Rules: []*banPolicy.Rule{{Name: "empty", Action: &ban}},
+	})
+	if !errors.Is(err, banPolicy.ErrEmptyRule) {
+		t.Errorf("expected ErrEmptyRule, got %v", err)
+		return
+	}
+
+	// including the unbanned users isn't a condition on its own.
+	err = engine.SetPolicy(banPolicy.GlobalChatId, &banPolicy.Policy{
+		Rules: []*banPolicy.Rule{{Name: "everyone", IncludeUnbanned: true, Action: &ban}},
+	})
+	if !errors.Is(err, banPolicy.ErrEmptyRule) {
+		t.Errorf("expected ErrEmptyRule for a rule with only include_unbanned, got %v", err)
+		return
+	}
+
+	// reason patterns match the same way as in the queries.
+	rule := &banPolicy.Rule{ReasonMatches: "crypto scam", Action: &ban}
+	err = banPolicy.CompileRule(rule)
+	if err != nil || !rule.Match(&sibylSystemGo.BanInfo{UserId: 1, Banned: true, Reason: "Crypto Scam"}) {
+		t.Errorf("expected the reason pattern to be case-insensitive, got %v", err)
+	}
+}
+
+func TestBanPolicy03(t *testing.T) {
+	engine, _ := banPolicy.NewEngine(nil)
+	minCoefficient := int64(300)
+	ban := sibylGuard.ActionBan
+	err := engine.SetPolicy(-100123, &banPolicy.Policy{
+		DefaultAction: sibylGuard.ActionMute,
+		Rules: []*banPolicy.Rule{
+			{Name: "lethal", MinCoefficient: &minCoefficient, Action: &ban},
+		},
+	})
+	if err != nil {
+		t.Errorf("failed to set the policy: %v", err)
+		return
+	}
+
+	bans := []sibylSystemGo.BanInfo{
+		{UserId: 1, Banned: true, CrimeCoefficient: 450},
+		{UserId: 2, Banned: true, CrimeCoefficient: 150},
+		{UserId: 3, Banned: true, CrimeCoefficient: 320},
+	}
+	forEach := func(fn func(info *sibylSystemGo.BanInfo) bool) {
+		for i := range bans {
+			if !fn(&bans[i]) {
+				return
+			}
+		}
+	}
+
+	report := engine.Simulate(-100123, forEach)
+	if report.Total != 3 || report.GetCount(sibylGuard.ActionBan) != 2 ||
+		report.GetCount(sibylGuard.ActionMute) != 1 || report.Rules["lethal"] != 2 ||
+		report.GetAffected() != 3 || len(report.Samples[sibylGuard.ActionBan]) != 2 {
+		t.Errorf("unexpected simulation report: %+v", report)
+	}
+}
@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	sibylSystemGo "github.com/ALiwoto/sibylSystemGo/sibylSystem"
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/banQuery"
+)
+
+func TestBanQuery01(t *testing.T) {
+	now := time.Date(2022, 6, 10, 0, 0, 0, 0, time.UTC)
+	result := &sibylSystemGo.GetBansResult{
+		Users: []sibylSystemGo.BanInfo{
+			{
+				UserId:           1,
+				Reason:           "Spam bot adding members",
+				BannedBy:         777,
+				CrimeCoefficient: 450,
+				Date:             mustParseTime("2022-06-08T10:00:00Z"),
+				BanFlags:         []sibylSystemGo.BanFlag{sibylSystemGo.BanFlagSpamBot, sibylSystemGo.BanFlagMassAdd},
+			},
+			{
+				UserId:           2,
+				Reason:           "spam bot",
+				BannedBy:         777,
+				CrimeCoefficient: 250,
+				Date:             mustParseTime("2022-06-08T10:00:00Z"),
+				BanFlags:         []sibylSystemGo.BanFlag{sibylSystemGo.BanFlagSpamBot},
+			},
+			{
+				UserId:           3,
+				Reason:           "spam bot, long ago",
+				BannedBy:         777,
+				CrimeCoefficient: 450,
+				Date:             mustParseTime("2022-01-01T10:00:00Z"),
+				BanFlags:         []sibylSystemGo.BanFlag{sibylSystemGo.BanFlagSpamBot},
+			},
+			{
+				UserId:           4,
+				Reason:           "Spam bot by another enforcer",
+				BannedBy:         888,
+				CrimeCoefficient: 450,
+				Date:             mustParseTime("2022-06-09T10:00:00Z"),
+				BanFlags:         []sibylSystemGo.BanFlag{sibylSystemGo.BanFlagSpamBot},
+				TargetType:       sibylSystemGo.EntityTypeBot,
+			},
+		},
+	}
+
+	filter, err := banQuery.ParseAt(`flag:spambot by:777 since:7d coef>300 reason~^spam "adding"`, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matched := filter.Apply(result)
+	if len(matched) != 1 || matched[0].UserId != 1 {
+		t.Errorf("unexpected matched bans: %v", matched)
+	}
+
+	filter = banQuery.NewFilter().AllFlags(sibylSystemGo.BanFlagSpamBot).
+		TargetType(sibylSystemGo.EntityTypeBot)
+	matched = filter.Apply(result)
+	if len(matched) != 1 || matched[0].UserId != 4 {
+		t.Errorf("unexpected matched bans: %v", matched)
+	}
+
+	for _, query := range []string{"unknown:1", "coef>abc", `reason:"open`, "since:yesterday"} {
+		if _, err = banQuery.Parse(query); err == nil {
+			t.Errorf("expected query %q to be invalid", query)
+		}
+	}
+}
+
+func mustParseTime(value string) sibylSystemGo.SibylTime {
+	t, err := sibylSystemGo.ParseSibylTime(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	sibylSystemGo "github.com/ALiwoto/sibylSystemGo/sibylSystem"
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/sibylRender"
+)
+
+func getRenderTestInfo() *sibylSystemGo.GetInfoResult {
+	return &sibylSystemGo.GetInfoResult{
+		UserId:           1234,
+		Banned:           true,
+		Reason:           "spam <links> & ads!",
+		BannedBy:         42,
+		CrimeCoefficient: 250,
+		Date:             sibylSystemGo.NewSibylTime(time.Date(2022, 6, 8, 10, 0, 0, 0, time.UTC)),
+		BanFlags:         []sibylSystemGo.BanFlag{sibylSystemGo.BanFlagSpam, sibylSystemGo.BanFlagRaid},
+		BanSourceUrl:     "https://t.me/c/1/2",
+	}
+}
+
+func TestRender01(t *testing.T) {
+	renderer, err := sibylRender.NewRenderer(sibylRender.FormatHTML, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	text, err := renderer.RenderInfo(getRenderTestInfo())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expected := []string{
+		`<b>User</b>: <a href="tg://user?id=1234">1234</a>`,
+		"Banned: Yes",
+		"Reason: spam &lt;links&gt; &amp; ads!",
+		"Flags: <code>SPAM</code>, <code>RAID</code>",
+		"Crime coefficient: <code>250</code> (Paralyzer)",
+		"Date: 2022-06-08",
+		`Source: <a href="https://t.me/c/1/2">link</a>`,
+	}
+	for _, current := range expected {
+		if !strings.Contains(text, current) {
+			t.Errorf("expected %q in:\n%s", current, text)
+		}
+	}
+}
+
+func TestRender02(t *testing.T) {
+	renderer, err := sibylRender.NewRenderer(sibylRender.FormatMarkdownV2, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	text, err := renderer.RenderBan(&sibylSystemGo.BanResult{CurrentBan: &sibylSystemGo.BanInfo{
+		UserId:           1234,
+		Banned:           true,
+		Reason:           "spam (ads).",
+		CrimeCoefficient: 150,
+	}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expected := []string{
+		"*User has been banned*",
+		"*User*: [1234](tg://user?id=1234)",
+		`Reason: spam \(ads\)\.`,
+		"Crime coefficient: `150` \\(Latent criminal\\)",
+	}
+	for _, current := range expected {
+		if !strings.Contains(text, current) {
+			t.Errorf("expected %q in:\n%s", current, text)
+		}
+	}
+}
+
+func TestRender03(t *testing.T) {
+	renderer, err := sibylRender.NewRenderer(sibylRender.FormatPlain, &sibylRender.RendererConfig{
+		Templates: map[string]string{
+			sibylRender.TemplateInfo: `{{.UserId}} is {{if .Banned}}banned ({{cuteFlags .BanFlags}}, {{estimate .CrimeCoefficient}}){{else}}clean{{end}}`,
+		},
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	text, err := renderer.RenderInfo(getRenderTestInfo())
+	if err != nil || text != "1234 is banned (spam and raid, over 200)" {
+		t.Errorf("unexpected output: %q, %v", text, err)
+		return
+	}
+
+	_, err = renderer.Render("missing", nil)
+	if err != sibylRender.ErrUnknownTemplate {
+		t.Errorf("expected ErrUnknownTemplate, got %v", err)
+	}
+
+	text, err = renderer.RenderStats(&sibylSystemGo.GetStatsResult{BannedCount: 10, SpamBanCount: 4})
+	if err != nil || !strings.Contains(text, "Banned users: 10") || !strings.Contains(text, "spam: 4") {
+		t.Errorf("unexpected stats output: %q, %v", text, err)
+	}
+}
+
+func TestRender04(t *testing.T) {
+	renderer, err := sibylRender.NewRenderer(sibylRender.FormatHTML, &sibylRender.RendererConfig{
+		Templates: map[string]string{
+			"nested": `{{link (bold .Reason) .BanSourceUrl}} {{bold .Message}} {{.Reason}}`,
+		},
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	info := getRenderTestInfo()
+	info.Reason = "<i>fake</i>"
+	info.Message = "a<u>b</u>"
+
+	text, err := renderer.Render("nested", info)
+	expected := `<a href="https://t.me/c/1/2"><b>&lt;i&gt;fake&lt;/i&gt;</b></a> ` +
+		`<b>a&lt;u&gt;b&lt;/u&gt;</b> &lt;i&gt;fake&lt;/i&gt;`
+	if err != nil || text != expected {
+		t.Errorf("unexpected output: %q, %v", text, err)
+		return
+	}
+
+	if info.Reason != "<i>fake</i>" {
+		t.Errorf("the data shouldn't be changed, got %q", info.Reason)
+	}
+}
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	sibylSystemGo "github.com/ALiwoto/sibylSystemGo/sibylSystem"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSibylTime01(t *testing.T) {
+	inputs := map[string]time.Time{
+		`"2022-06-08T10:00:00.5+04:30"`: time.Date(2022, 6, 8, 5, 30, 0, 5e8, time.UTC),
+		`"2022-06-08 10:00:00"`:         time.Date(2022, 6, 8, 10, 0, 0, 0, time.UTC),
+		`"2022-06-08 at 10:00:00"`:      time.Date(2022, 6, 8, 10, 0, 0, 0, time.UTC),
+		`1654682400`:                    time.Date(2022, 6, 8, 10, 0, 0, 0, time.UTC),
+		`"not a date"`:                  {},
+	}
+
+	for input, expected := range inputs {
+		info := new(sibylSystemGo.BanInfo)
+		err := json.Unmarshal([]byte(`{"user_id":1,"date":`+input+`}`), info)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !info.Date.Time.Equal(expected) {
+			t.Errorf("%s: expected %v, got %v", input, expected, info.Date.Time)
+		}
+
+		b, err := json.Marshal(info.Date)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(b) != input {
+			t.Errorf("%s: marshalled back as %s", input, b)
+		}
+	}
+
+	banned := sibylSystemGo.NewSibylTime(time.Date(2022, 6, 8, 10, 0, 0, 0, time.UTC))
+	now := time.Date(2022, 6, 11, 12, 0, 0, 0, time.UTC)
+	if relative := banned.FormatRelative(now); relative != "3 days ago" {
+		t.Errorf("unexpected relative time: %s", relative)
+	}
+
+	tehran := time.FixedZone("IRST", int((3*time.Hour + 30*time.Minute).Seconds()))
+	if display := banned.FormatIn(tehran, ""); display != "2022-06-08 13:30:00 IRST" {
+		t.Errorf("unexpected display time: %s", display)
+	}
+}
+
+func TestSibylTime02(t *testing.T) {
+	info := new(sibylSystemGo.BanInfo)
+	err := json.Unmarshal([]byte(`{"user_id":1,"date":"2022-06-08 10:00:00"}`), info)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a changed time isn't marshalled as the original value anymore.
+	info.Date.Time = info.Date.Add(time.Hour)
+	b, err := json.Marshal(info.Date)
+	if err != nil || string(b) != `"2022-06-08T11:00:00Z"` {
+		t.Errorf("unexpected changed time: %s, %v", b, err)
+		return
+	}
+
+	if info.Date.String() != "2022-06-08T11:00:00Z" {
+		t.Errorf("unexpected string of the changed time: %s", info.Date.String())
+		return
+	}
+
+	// text encodings use the original value too.
+	type record struct {
+		Date sibylSystemGo.SibylTime `yaml:"date"`
+	}
+	original, _ := sibylSystemGo.ParseSibylTime("2022-06-08 at 10:00:00")
+	b, err = yaml.Marshal(&record{Date: original})
+	if err != nil || string(b) != "date: 2022-06-08 at 10:00:00\n" {
+		t.Errorf("unexpected yaml: %q, %v", b, err)
+		return
+	}
+
+	decoded := new(record)
+	err = yaml.Unmarshal([]byte("date: 1654682400\n"), decoded)
+	if err != nil || !decoded.Date.Equal(time.Date(2022, 6, 8, 10, 0, 0, 0, time.UTC)) ||
+		decoded.Date.String() != "1654682400" {
+		t.Errorf("unexpected yaml time: %v, %v", decoded.Date, err)
+	}
+}
@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sibylSystemGo "github.com/ALiwoto/sibylSystemGo/sibylSystem"
+	"github.com/ALiwoto/sibylSystemGo/sibylSystem/banMirror"
+)
+
+// getVerdictTestRoutes returns the routes of a server which fails with
+// bad gateway while online is zero.
+func getVerdictTestRoutes(online *int32) sibylRoutes {
+	return sibylRoutes{
+		"getBans": func(w http.ResponseWriter, r *http.Request) {
+			if atomic.LoadInt32(online) == 0 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+
+			_, _ = w.Write([]byte(`{"success":true,"result":{"users":[` +
+				`{"user_id":300,"banned":true,"reason":"raid","crime_coefficient":300}]}}`))
+		},
+		"getInfo": func(w http.ResponseWriter, r *http.Request) {
+			if atomic.LoadInt32(online) == 0 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+
+			if r.Header.Get("user-id") == "100" {
+				_, _ = w.Write([]byte(`{"success":true,"result":{"user_id":100,"banned":true,"reason":"spam"}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"success":true,"result":{"user_id":` + r.Header.Get("user-id") + `,"banned":false}}`))
+		},
+	}
+}
+
+func TestBanChecker01(t *testing.T) {
+	var online int32 = 1
+	client, _ := newTestClient(t, getVerdictTestRoutes(&online), &sibylSystemGo.SibylConfig{
+		InfoCacheTTL: time.Minute,
+	})
+
+	checker, err := sibylSystemGo.NewBanChecker(client, &sibylSystemGo.BanCheckerConfig{
+		Policy: sibylSystemGo.DegradeFailClosed,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	verdict := checker.IsBanned(context.Background(), 100)
+	if !verdict.Banned || verdict.Source != sibylSystemGo.VerdictSourceLive || verdict.IsDegraded() {
+		t.Errorf("unexpected live verdict: %+v", verdict)
+		return
+	}
+
+	verdict = checker.IsBanned(context.Background(), 100)
+	if !verdict.Banned || verdict.Source != sibylSystemGo.VerdictSourceCache {
+		t.Errorf("expected a cached verdict, got %s", verdict.Source)
+		return
+	}
+
+	atomic.StoreInt32(&online, 0)
+	verdict = checker.IsBanned(context.Background(), 200)
+	if !verdict.Banned || verdict.Source != sibylSystemGo.VerdictSourceDefault || verdict.Err == nil {
+		t.Errorf("expected a fail closed verdict, got %+v", verdict)
+	}
+}
+
+func TestBanChecker02(t *testing.T) {
+	var online int32 = 1
+	client, _ := newTestClient(t, getVerdictTestRoutes(&online), nil)
+
+	mirror, err := banMirror.NewMirror(client, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if _, err = mirror.Sync(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	checker, err := sibylSystemGo.NewBanChecker(client, &sibylSystemGo.BanCheckerConfig{
+		Policy:        sibylSystemGo.DegradeUseStale,
+		MaxStaleness:  time.Hour,
+		StaleFallback: sibylSystemGo.DegradeFailClosed,
+		Mirror:        mirror,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if verdict := checker.IsBanned(context.Background(), 100); verdict.Source != sibylSystemGo.VerdictSourceLive {
+		t.Errorf("expected a live verdict, got %s", verdict.Source)
+		return
+	}
+
+	atomic.StoreInt32(&online, 0)
+
+	// the last known info of the user is used.
+	verdict := checker.IsBanned(context.Background(), 100)
+	if !verdict.Banned || verdict.Source != sibylSystemGo.VerdictSourceCache || !verdict.IsDegraded() {
+		t.Errorf("expected a stale cached verdict, got %+v", verdict)
+		return
+	}
+
+	// users which have never been looked up are checked in the mirror.
+	verdict = checker.IsBanned(context.Background(), 300)
+	if !verdict.Banned || verdict.Source != sibylSystemGo.VerdictSourceMirror || verdict.Ban == nil {
+		t.Errorf("expected a banned mirror verdict, got %+v", verdict)
+		return
+	}
+
+	verdict = checker.IsBanned(context.Background(), 400)
+	if verdict.Banned || verdict.Source != sibylSystemGo.VerdictSourceMirror {
+		t.Errorf("expected an unbanned mirror verdict, got %+v", verdict)
+	}
+}
+
+func TestBanChecker03(t *testing.T) {
+	var banned int32
+	client, _ := newTestClient(t, sibylRoutes{
+		"addBan": func(w http.ResponseWriter, r *http.Request) {
+			atomic.StoreInt32(&banned, 1)
+			_, _ = w.Write([]byte(`{"success":true,"result":{"previous_ban":null,` +
+				`"current_ban":{"user_id":500,"banned":true,"reason":"raid"}}}`))
+		},
+		"getInfo": func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"success":true,"result":{"user_id":500,"banned":` +
+				strconv.FormatBool(atomic.LoadInt32(&banned) == 1) + `}}`))
+		},
+	}, &sibylSystemGo.SibylConfig{
+		InfoCacheTTL: time.Minute,
+	})
+
+	checker, err := sibylSystemGo.NewBanChecker(client, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if verdict := checker.IsBanned(context.Background(), 500); verdict.Banned {
+		t.Errorf("expected the user not to be banned yet, got %+v", verdict)
+		return
+	}
+
+	if _, err = client.Ban(500, "raid", nil); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// the cached info of the user is dropped by the ban.
+	verdict := checker.IsBanned(context.Background(), 500)
+	if !verdict.Banned || verdict.Source != sibylSystemGo.VerdictSourceLive {
+		t.Errorf("expected a live banned verdict, got %+v", verdict)
+	}
+}